@@ -0,0 +1,352 @@
+package goway
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Memoria máxima para formularios multipart; el resto va a ficheros temporales
+const defaultMultipartMemory = 32 << 20
+
+// Error de binding de un campo concreto
+type FieldError struct {
+	Field string // Nombre del campo en la petición
+	Value string // Valor recibido
+	Err   error
+}
+
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("invalid value %q for field %q: %v", e.Value, e.Field, e.Err)
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+var (
+	fileHeaderType = reflect.TypeOf((*multipart.FileHeader)(nil))
+	timeType       = reflect.TypeOf(time.Time{})
+)
+
+var errRequiredHeader = errors.New("required header is missing")
+
+// Error al decodificar el cuerpo JSON en Body o BindSchema
+type bindingError struct {
+	err error
+}
+
+func (e *bindingError) Error() string {
+	return e.err.Error()
+}
+
+func (e *bindingError) Unwrap() error {
+	return e.err
+}
+
+// Decodificar el cuerpo marcando los errores de JSON como errores de binding
+func decodeJSONBody(r *http.Request, body []byte, v any) error {
+	err := unmarshalJSON(r, body, v)
+	if err == nil || errors.As(err, new(*CustomError)) {
+		return err
+	}
+	return &bindingError{err}
+}
+
+// Convertir un error de binding en una respuesta 400, con el
+// BindErrorFormatter del servidor si hay uno
+func bindErrorResponse(r *http.Request, err error) *CustomError {
+	if g := goWayFrom(r); g != nil && g.BindErrorFormatter != nil {
+		if customErr := g.BindErrorFormatter(err); customErr != nil {
+			return customErr
+		}
+	}
+	return NewCustomError(bindErrorMessage(err), http.StatusBadRequest)
+}
+
+// Mensaje por defecto: nombra el campo sin los detalles de strconv o json
+func bindErrorMessage(err error) string {
+	var fieldErr *FieldError
+	var typeErr *json.UnmarshalTypeError
+	var syntaxErr *json.SyntaxError
+	switch {
+	case errors.As(err, &fieldErr) && errors.Is(fieldErr.Err, errRequiredHeader):
+		return fmt.Sprintf("missing required header %q", fieldErr.Field)
+	case errors.As(err, &fieldErr):
+		return fmt.Sprintf("invalid value %q for field %q", fieldErr.Value, fieldErr.Field)
+	case errors.As(err, &typeErr) && typeErr.Field != "":
+		return fmt.Sprintf("invalid type for field %q: expected %s", typeErr.Field, typeErr.Type)
+	case errors.As(err, &syntaxErr):
+		return fmt.Sprintf("invalid JSON at offset %d", syntaxErr.Offset)
+	}
+	return "invalid request body"
+}
+
+// Rellenar v (puntero a struct) con los campos del formulario, urlencoded o
+// multipart, según las etiquetas `form:"campo"`. Los slices reciben todos
+// los valores repetidos y los campos *multipart.FileHeader (o slices de
+// ellos) reciben los ficheros subidos. Los campos sin etiqueta se ignoran.
+//
+// Los structs anidados se rellenan con claves "filtro.desde" o
+// "filtro[desde]", los time.Time se leen con el formato de la etiqueta
+// `time_format` (RFC 3339 por defecto) y los punteros quedan a nil si el
+// campo no llega. Un valor que no se puede convertir devuelve un *FieldError.
+func (c *GoWayContext) BindForm(v any) error {
+	if err := c.parseForm(); err != nil {
+		return err
+	}
+	var files map[string][]*multipart.FileHeader
+	if c.r.MultipartForm != nil {
+		files = c.r.MultipartForm.File
+	}
+	return bindStruct(v, "form", func(field reflect.StructField, key string, dst reflect.Value) error {
+		if isFileField(field.Type) {
+			if fh := files[key]; len(fh) > 0 {
+				if field.Type.Kind() == reflect.Slice {
+					dst.Set(reflect.ValueOf(fh))
+				} else {
+					dst.Set(reflect.ValueOf(fh[0]))
+				}
+			}
+			return nil
+		}
+		return setValues(dst, key, lookupValues(c.r.Form, key), field.Tag.Get("time_format"))
+	})
+}
+
+// Rellenar v (puntero a struct) con los parámetros de la query según las
+// etiquetas `query:"campo"`, con las mismas reglas que BindForm
+func (c *GoWayContext) BindQuery(v any) error {
+	query := c.r.URL.Query()
+	return bindStruct(v, "query", func(field reflect.StructField, key string, dst reflect.Value) error {
+		return setValues(dst, key, lookupValues(query, key), field.Tag.Get("time_format"))
+	})
+}
+
+// Rellenar v (puntero a struct) con las cabeceras de la petición según las
+// etiquetas `header:"X-Tenant-ID"`, sin distinguir mayúsculas. Con
+// `header:"X-Tenant-ID,required"` una cabecera ausente devuelve un
+// *FieldError.
+func (c *GoWayContext) BindHeader(v any) error {
+	return bindStruct(v, "header", func(field reflect.StructField, key string, dst reflect.Value) error {
+		values := c.r.Header.Values(key)
+		if len(values) == 0 && hasTagOption(field.Tag.Get("header"), "required") {
+			return &FieldError{Field: key, Err: errRequiredHeader}
+		}
+		return setValues(dst, key, values, field.Tag.Get("time_format"))
+	})
+}
+
+// Indica si la etiqueta lleva la opción indicada tras el nombre
+func hasTagOption(tag, option string) bool {
+	_, opts, _ := strings.Cut(tag, ",")
+	for _, opt := range strings.Split(opts, ",") {
+		if strings.TrimSpace(opt) == option {
+			return true
+		}
+	}
+	return false
+}
+
+// Valores de una clave anidada, escrita como "a.b" o como "a[b]"
+func lookupValues(values url.Values, key string) []string {
+	if v, ok := values[key]; ok || !strings.Contains(key, ".") {
+		return v
+	}
+	parts := strings.Split(key, ".")
+	return values[parts[0]+"["+strings.Join(parts[1:], "][")+"]"]
+}
+
+func isFileField(t reflect.Type) bool {
+	return t == fileHeaderType || t.Kind() == reflect.Slice && t.Elem() == fileHeaderType
+}
+
+// Recorrer los campos con la etiqueta tag y delegar en fill la asignación
+func bindStruct(v any, tag string, fill func(field reflect.StructField, key string, dst reflect.Value) error) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return errors.New("goway: bind target must be a non-nil pointer to a struct")
+	}
+	return bindFields(rv.Elem(), tag, "", fill)
+}
+
+// Los structs anidados (salvo time.Time) se recorren con su clave como prefijo
+func bindFields(rv reflect.Value, tag, prefix string, fill func(field reflect.StructField, key string, dst reflect.Value) error) error {
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		key, _, _ := strings.Cut(field.Tag.Get(tag), ",")
+		if key == "" || key == "-" || !field.IsExported() {
+			continue
+		}
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+		var err error
+		if field.Type.Kind() == reflect.Struct && field.Type != timeType {
+			err = bindFields(rv.Field(i), tag, key, fill)
+		} else {
+			err = fill(field, key, rv.Field(i))
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Asignar los valores recibidos al campo, convirtiendo al tipo del campo.
+// Sin valores el campo queda como está.
+func setValues(dst reflect.Value, key string, values []string, layout string) error {
+	if len(values) == 0 {
+		return nil
+	}
+	if dst.Kind() == reflect.Pointer {
+		ptr := reflect.New(dst.Type().Elem())
+		if err := setValues(ptr.Elem(), key, values, layout); err != nil {
+			return err
+		}
+		dst.Set(ptr)
+		return nil
+	}
+	if dst.Kind() == reflect.Slice {
+		slice := reflect.MakeSlice(dst.Type(), len(values), len(values))
+		for i, value := range values {
+			if err := setValue(slice.Index(i), value, layout); err != nil {
+				return &FieldError{Field: key, Value: value, Err: err}
+			}
+		}
+		dst.Set(slice)
+		return nil
+	}
+	if err := setValue(dst, values[0], layout); err != nil {
+		return &FieldError{Field: key, Value: values[0], Err: err}
+	}
+	return nil
+}
+
+// Convertir un valor de texto al tipo de dst. layout es el formato para
+// time.Time (RFC 3339 si está vacío).
+func setValue(dst reflect.Value, value, layout string) error {
+	if dst.Type() == timeType {
+		if layout == "" {
+			layout = time.RFC3339
+		}
+		t, err := time.Parse(layout, value)
+		if err != nil {
+			return err
+		}
+		dst.Set(reflect.ValueOf(t))
+		return nil
+	}
+	switch dst.Kind() {
+	case reflect.String:
+		dst.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		dst.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(value, 10, dst.Type().Bits())
+		if err != nil {
+			return err
+		}
+		dst.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(value, 10, dst.Type().Bits())
+		if err != nil {
+			return err
+		}
+		dst.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(value, dst.Type().Bits())
+		if err != nil {
+			return err
+		}
+		dst.SetFloat(f)
+	default:
+		return fmt.Errorf("unsupported field type %s", dst.Type())
+	}
+	return nil
+}
+
+// Rellenar v (puntero a struct) desde varias fuentes a la vez, según la
+// etiqueta de cada campo: `param:` (path), `query:`, `header:` y `json:`
+// (cuerpo). Se aplican en ese orden, así que si un campo tiene varias
+// etiquetas gana la última fuente que lo trae; el cuerpo va siempre al final
+// y solo se lee si la petición tiene cuerpo.
+//
+//	type UpdateUser struct {
+//		ID     int64  `param:"id"`
+//		Tenant string `header:"X-Tenant-ID"`
+//		Name   string `json:"name"`
+//	}
+func (c *GoWayContext) BindAll(v any) error {
+	err := bindStruct(v, "param", func(field reflect.StructField, key string, dst reflect.Value) error {
+		var values []string
+		if value := c.r.PathValue(key); value != "" {
+			values = []string{value}
+		}
+		return setValues(dst, key, values, field.Tag.Get("time_format"))
+	})
+	if err != nil {
+		return err
+	}
+	if err := c.BindQuery(v); err != nil {
+		return err
+	}
+	if err := c.BindHeader(v); err != nil {
+		return err
+	}
+	if c.r.ContentLength == 0 {
+		return nil
+	}
+	return c.Body(v)
+}
+
+// Decodificar el cuerpo JSON en un valor nuevo de tipo T, con las mismas
+// comprobaciones que Body. Si falla devuelve el valor cero y el error, que
+// devuelto desde un manejador con WithError se responde con 400.
+//
+//	user, err := goway.BindJSON[CreateUser](c)
+func BindJSON[T any](c *GoWayContext) (T, error) {
+	var v T
+	if err := c.Body(&v); err != nil {
+		var zero T
+		return zero, err
+	}
+	return v, nil
+}
+
+// Decodificar el cuerpo JSON en un mapa genérico, para procesar cargas sin
+// un struct fijo. Aplica los mismos límites que Body (MaxBodySize,
+// MaxJSONDepth). Si el cuerpo no es un objeto JSON devuelve un CustomError 400.
+func (c *GoWayContext) BodyMap() (map[string]any, error) {
+	body, err := c.readJSON()
+	if err != nil {
+		return nil, err
+	}
+	if trimmed := bytes.TrimSpace(body); len(trimmed) == 0 || trimmed[0] != '{' {
+		return nil, NewCustomError("request body must be a JSON object", http.StatusBadRequest)
+	}
+	var m map[string]any
+	if err := decodeJSONBody(c.r, body, &m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Todos los parámetros de la query, con todos los valores de los repetidos
+func (c *GoWayContext) QueryMapAll() map[string][]string {
+	return c.r.URL.Query()
+}
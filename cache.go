@@ -0,0 +1,205 @@
+package goway
+
+import (
+	"container/list"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Opciones de Cache-Control
+type CacheOption func(*cacheControl)
+
+type cacheControl struct {
+	public         bool
+	private        bool
+	noStore        bool
+	mustRevalidate bool
+}
+
+// Permitir que caches compartidas (CDN, proxies) guarden la respuesta
+func CachePublic() CacheOption {
+	return func(c *cacheControl) { c.public = true }
+}
+
+// Solo el navegador del cliente puede guardar la respuesta
+func CachePrivate() CacheOption {
+	return func(c *cacheControl) { c.private = true }
+}
+
+// No guardar la respuesta en ninguna cache
+func CacheNoStore() CacheOption {
+	return func(c *cacheControl) { c.noStore = true }
+}
+
+// Obligar a revalidar la respuesta cuando caduque
+func CacheMustRevalidate() CacheOption {
+	return func(c *cacheControl) { c.mustRevalidate = true }
+}
+
+// Middleware que añade Cache-Control a las respuestas. Si el manejador ya
+// fijó la cabecera se respeta la suya.
+func CacheControlMiddleware(maxAge time.Duration, opts ...CacheOption) func(http.Handler) http.Handler {
+	cfg := &cacheControl{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	value := cfg.header(maxAge)
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			hw := &hookWriter{ResponseWriter: w, before: func(h http.Header) {
+				if h.Get("Cache-Control") == "" {
+					h.Set("Cache-Control", value)
+				}
+			}}
+			next.ServeHTTP(hw, r)
+			// Si el manejador no escribió nada net/http envía un 200 vacío
+			// después, con las cabeceras tal como estén
+			hw.run()
+		})
+	}
+}
+
+func (c *cacheControl) header(maxAge time.Duration) string {
+	if c.noStore {
+		return "no-store"
+	}
+	var parts []string
+	switch {
+	case c.public:
+		parts = append(parts, "public")
+	case c.private:
+		parts = append(parts, "private")
+	}
+	parts = append(parts, "max-age="+strconv.Itoa(int(maxAge.Seconds())))
+	if c.mustRevalidate {
+		parts = append(parts, "must-revalidate")
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Respuesta guardada en CacheMiddleware
+type cachedResponse struct {
+	key     string
+	status  int
+	header  http.Header
+	body    []byte
+	expires time.Time
+}
+
+// Cache LRU en memoria, segura para uso concurrente
+type responseCache struct {
+	mu         sync.Mutex
+	maxEntries int
+	ll         *list.List
+	items      map[string]*list.Element
+}
+
+func (c *responseCache) get(key string) (*cachedResponse, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+	entry := el.Value.(*cachedResponse)
+	if time.Now().After(entry.expires) {
+		c.ll.Remove(el)
+		delete(c.items, key)
+		return nil, false
+	}
+	c.ll.MoveToFront(el)
+	return entry, true
+}
+
+func (c *responseCache) add(entry *cachedResponse) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.items[entry.key]; ok {
+		el.Value = entry
+		c.ll.MoveToFront(el)
+		return
+	}
+	c.items[entry.key] = c.ll.PushFront(entry)
+	if c.ll.Len() > c.maxEntries {
+		oldest := c.ll.Back()
+		c.ll.Remove(oldest)
+		delete(c.items, oldest.Value.(*cachedResponse).key)
+	}
+}
+
+// Middleware que guarda en memoria las respuestas 200 de GET, por URL
+// completa, durante ttl. Como mucho guarda maxEntries respuestas y descarta
+// las menos usadas. Las peticiones con Cache-Control: no-cache lo saltan.
+// Pensado para aplicarse a rutas concretas.
+func CacheMiddleware(ttl time.Duration, maxEntries int) func(http.Handler) http.Handler {
+	if maxEntries <= 0 {
+		maxEntries = 1
+	}
+	cache := &responseCache{
+		maxEntries: maxEntries,
+		ll:         list.New(),
+		items:      make(map[string]*list.Element),
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodGet || strings.Contains(r.Header.Get("Cache-Control"), "no-cache") {
+				next.ServeHTTP(w, r)
+				return
+			}
+			key := r.Host + r.URL.RequestURI()
+			if entry, ok := cache.get(key); ok {
+				for k, v := range entry.header {
+					w.Header()[k] = v
+				}
+				w.WriteHeader(entry.status)
+				w.Write(entry.body)
+				return
+			}
+
+			rec := &recordWriter{ResponseWriter: w}
+			next.ServeHTTP(rec, r)
+			// No guardar respuestas con cookies, son propias de cada cliente
+			if rec.status == http.StatusOK && w.Header().Get("Set-Cookie") == "" {
+				cache.add(&cachedResponse{
+					key:     key,
+					status:  rec.status,
+					header:  w.Header().Clone(),
+					body:    rec.body.Bytes(),
+					expires: time.Now().Add(ttl),
+				})
+			}
+		})
+	}
+}
+
+// Fijar Last-Modified y, si el cliente ya tiene esa versión según
+// If-Modified-Since, responder 304 y devolver true para que el manejador no
+// envíe el cuerpo. Las fechas se comparan a segundos, que es la precisión de
+// la cabecera. Solo aplica a GET y HEAD, y se ignora si la petición trae
+// If-None-Match (que tiene prioridad).
+//
+//	if c.LastModified(article.UpdatedAt) {
+//		return
+//	}
+func (c *GoWayContext) LastModified(t time.Time) bool {
+	if t.IsZero() {
+		return false
+	}
+	t = t.UTC().Truncate(time.Second)
+	c.w.Header().Set("Last-Modified", t.Format(http.TimeFormat))
+	if c.r.Method != http.MethodGet && c.r.Method != http.MethodHead || c.r.Header.Get("If-None-Match") != "" {
+		return false
+	}
+	since, err := http.ParseTime(c.r.Header.Get("If-Modified-Since"))
+	if err != nil || t.After(since) {
+		return false
+	}
+	h := c.w.Header()
+	h.Del("Content-Type")
+	h.Del("Content-Length")
+	c.w.WriteHeader(http.StatusNotModified)
+	return true
+}
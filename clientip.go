@@ -0,0 +1,117 @@
+package goway
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// Cabeceras con la IP real del cliente en plataformas conocidas, para usar en
+// GoWay.TrustedPlatform
+const (
+	PlatformCloudflare      = "CF-Connecting-IP"
+	PlatformGoogleAppEngine = "X-Appengine-Remote-Addr"
+	PlatformFlyIO           = "Fly-Client-IP"
+)
+
+// IP del cliente. Si la conexión viene de un proxy de confianza (ver
+// TrustedProxies) se usa la cabecera de TrustedPlatform, o si no hay
+// X-Forwarded-For y X-Real-IP; si no, la dirección de la conexión.
+func (c *GoWayContext) ClientIP() string {
+	return clientIP(c.r)
+}
+
+func clientIP(r *http.Request) string {
+	if !fromTrustedProxy(r) {
+		return remoteIP(r)
+	}
+	if g := goWayFrom(r); g != nil && g.TrustedPlatform != "" {
+		if ip := strings.TrimSpace(r.Header.Get(g.TrustedPlatform)); ip != "" {
+			return ip
+		}
+	}
+	if fwd := r.Header.Get("X-Forwarded-For"); fwd != "" {
+		// Recorrer de derecha a izquierda saltando los proxies propios: la
+		// primera IP que no es de confianza es la que vio el último proxy
+		hops := strings.Split(fwd, ",")
+		for i := len(hops) - 1; i >= 0; i-- {
+			ip := strings.TrimSpace(hops[i])
+			if ip != "" && (i == 0 || !trustedIP(r, net.ParseIP(ip))) {
+				return ip
+			}
+		}
+	}
+	if ip := strings.TrimSpace(r.Header.Get("X-Real-IP")); ip != "" {
+		return ip
+	}
+	return remoteIP(r)
+}
+
+// IP de la conexión sin el puerto
+func remoteIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// Indica si la conexión viene de un proxy cuyas cabeceras X-Forwarded-* se
+// pueden creer: las redes de TrustedProxies o, si no hay, direcciones de
+// loopback. Las redes privadas no bastan: otra máquina de la red interna
+// podría falsificar las cabeceras.
+func fromTrustedProxy(r *http.Request) bool {
+	return trustedIP(r, net.ParseIP(remoteIP(r)))
+}
+
+func trustedIP(r *http.Request, ip net.IP) bool {
+	if ip == nil {
+		return false
+	}
+	g := goWayFrom(r)
+	if g == nil || len(g.TrustedProxies) == 0 {
+		return ip.IsLoopback()
+	}
+	for _, n := range g.trustedProxies(r) {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// Redes de TrustedProxies, interpretadas una sola vez. Si no se arrancó con
+// Run (por ejemplo en tests) se interpretan en la primera petición.
+func (g *GoWay) trustedProxies(r *http.Request) []*net.IPNet {
+	if nets := g.proxies.Load(); nets != nil {
+		return *nets
+	}
+	if err := g.parseTrustedProxies(); err != nil {
+		loggerFrom(r).Errorf("goway: %v", err)
+		g.proxies.Store(&[]*net.IPNet{})
+		return nil
+	}
+	return *g.proxies.Load()
+}
+
+func (g *GoWay) parseTrustedProxies() error {
+	nets := make([]*net.IPNet, 0, len(g.TrustedProxies))
+	for _, cidr := range g.TrustedProxies {
+		if ip := net.ParseIP(cidr); ip != nil {
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 8*net.IPv4len
+			}
+			nets = append(nets, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, n, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return fmt.Errorf("invalid trusted proxy %q: %w", cidr, err)
+		}
+		nets = append(nets, n)
+	}
+	g.proxies.Store(&nets)
+	return nil
+}
@@ -0,0 +1,49 @@
+package goway
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClientIP(t *testing.T) {
+	tests := []struct {
+		name     string
+		proxies  []string
+		platform string
+		remote   string
+		header   http.Header
+		want     string
+	}{
+		{"direct client", nil, "", "203.0.113.7:4000",
+			http.Header{"X-Forwarded-For": {"198.51.100.1"}}, "203.0.113.7"},
+		{"loopback proxy", nil, "", "127.0.0.1:4000",
+			http.Header{"X-Forwarded-For": {"198.51.100.1"}}, "198.51.100.1"},
+		{"private network not trusted by default", nil, "", "10.0.0.5:4000",
+			http.Header{"X-Forwarded-For": {"198.51.100.1"}}, "10.0.0.5"},
+		{"configured proxy", []string{"10.0.0.0/8"}, "", "10.0.0.5:4000",
+			http.Header{"X-Forwarded-For": {"198.51.100.1, 10.0.0.9"}}, "198.51.100.1"},
+		{"platform header from trusted proxy", []string{"10.0.0.0/8"}, PlatformCloudflare, "10.0.0.5:4000",
+			http.Header{"Cf-Connecting-Ip": {"198.51.100.2"}, "X-Forwarded-For": {"198.51.100.1"}}, "198.51.100.2"},
+		{"platform header from untrusted peer", []string{"10.0.0.0/8"}, PlatformCloudflare, "203.0.113.7:4000",
+			http.Header{"Cf-Connecting-Ip": {"198.51.100.2"}}, "203.0.113.7"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGoWay()
+			g.TrustedProxies = tt.proxies
+			g.TrustedPlatform = tt.platform
+			var got string
+			g.GET("/ip", func(c *GoWayContext) { got = c.ClientIP() })
+			r := httptest.NewRequest(http.MethodGet, "/ip", nil)
+			r.RemoteAddr = tt.remote
+			for k, v := range tt.header {
+				r.Header[k] = v
+			}
+			g.TestRequest(r)
+			if got != tt.want {
+				t.Errorf("ClientIP() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package goway
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+)
+
+// Codificar JSON con el Marshal del servidor (encoding/json si no hay)
+func marshalJSON(r *http.Request, v any) ([]byte, error) {
+	if g := goWayFrom(r); g != nil && g.Marshal != nil {
+		return g.Marshal(v)
+	}
+	return json.Marshal(v)
+}
+
+// Decodificar JSON con el Unmarshal del servidor (encoding/json si no hay).
+// Lo que venga después del primer valor, salvo espacios, es un error 400:
+// no todos los Unmarshal alternativos lo rechazan.
+func unmarshalJSON(r *http.Request, data []byte, v any) error {
+	if g := goWayFrom(r); g != nil && g.Unmarshal != nil {
+		if end := jsonValueEnd(data); end >= 0 && !isJSONSpace(data[end:]) {
+			return errTrailingJSON()
+		}
+		return g.Unmarshal(data, v)
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if err := dec.Decode(v); err != nil {
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			// Mismo *json.SyntaxError que da json.Unmarshal con un cuerpo
+			// vacío o cortado; lo comprueba sin tocar v
+			return json.Unmarshal(data, v)
+		}
+		return err
+	}
+	if !isJSONSpace(data[dec.InputOffset():]) {
+		return errTrailingJSON()
+	}
+	return nil
+}
+
+func errTrailingJSON() error {
+	return NewCustomError("unexpected data after JSON body", http.StatusBadRequest)
+}
+
+// Indica si b solo contiene espacios en blanco de JSON
+func isJSONSpace(b []byte) bool {
+	for _, c := range b {
+		if c != ' ' && c != '\t' && c != '\n' && c != '\r' {
+			return false
+		}
+	}
+	return true
+}
+
+// Posición donde termina el primer valor JSON de data, recorriendo los bytes
+// sin decodificarlos. Devuelve -1 si no hay un valor completo: el error de
+// sintaxis lo da el propio Unmarshal.
+func jsonValueEnd(data []byte) int {
+	i := len(data) - len(bytes.TrimLeft(data, " \t\n\r"))
+	if i == len(data) {
+		return -1
+	}
+	switch data[i] {
+	case '{', '[', '"':
+		depth := 0
+		inString, escaped := false, false
+		for ; i < len(data); i++ {
+			b := data[i]
+			switch {
+			case inString:
+				switch {
+				case escaped:
+					escaped = false
+				case b == '\\':
+					escaped = true
+				case b == '"':
+					inString = false
+				}
+			case b == '"':
+				inString = true
+			case b == '{' || b == '[':
+				depth++
+			case b == '}' || b == ']':
+				depth--
+			}
+			if depth == 0 && !inString {
+				return i + 1
+			}
+		}
+		return -1
+	}
+	// Número, true, false o null: hasta el primer espacio o delimitador
+	for ; i < len(data); i++ {
+		switch data[i] {
+		case ' ', '\t', '\n', '\r', '{', '}', '[', ']', ',', ':', '"':
+			return i
+		}
+	}
+	return i
+}
+
+// Escribir v como JSON terminado en salto de línea, igual que json.Encoder
+func encodeJSON(w http.ResponseWriter, r *http.Request, v any) error {
+	data, err := marshalJSON(r, v)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(data, '\n'))
+	return err
+}
@@ -0,0 +1,127 @@
+package goway
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+)
+
+type benchUser struct {
+	ID     int    `json:"id"`
+	Name   string `json:"name"`
+	Email  string `json:"email"`
+	Active bool   `json:"active"`
+}
+
+// Codificación escrita a mano para benchUser, como la que generan easyjson
+// o ffjson: sin reflexión
+func (u *benchUser) appendJSON(b []byte) []byte {
+	b = append(b, `{"id":`...)
+	b = strconv.AppendInt(b, int64(u.ID), 10)
+	b = append(b, `,"name":`...)
+	b = strconv.AppendQuote(b, u.Name)
+	b = append(b, `,"email":`...)
+	b = strconv.AppendQuote(b, u.Email)
+	b = append(b, `,"active":`...)
+	b = strconv.AppendBool(b, u.Active)
+	return append(b, '}')
+}
+
+// Decodificación a mano de un objeto plano con las claves de benchUser
+func (u *benchUser) parseJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if len(data) < 2 || data[0] != '{' || data[len(data)-1] != '}' {
+		return errors.New("benchUser: not an object")
+	}
+	for _, field := range bytes.Split(data[1:len(data)-1], []byte(",")) {
+		key, value, ok := bytes.Cut(field, []byte(":"))
+		if !ok {
+			return errors.New("benchUser: missing colon")
+		}
+		var err error
+		switch string(bytes.TrimSpace(key)) {
+		case `"id"`:
+			u.ID, err = strconv.Atoi(string(bytes.TrimSpace(value)))
+		case `"name"`:
+			u.Name, err = strconv.Unquote(string(bytes.TrimSpace(value)))
+		case `"email"`:
+			u.Email, err = strconv.Unquote(string(bytes.TrimSpace(value)))
+		case `"active"`:
+			u.Active, err = strconv.ParseBool(string(bytes.TrimSpace(value)))
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func fastMarshal(v any) ([]byte, error) {
+	if u, ok := v.(*benchUser); ok {
+		return u.appendJSON(make([]byte, 0, 96)), nil
+	}
+	return json.Marshal(v)
+}
+
+func fastUnmarshal(data []byte, v any) error {
+	if u, ok := v.(*benchUser); ok {
+		return u.parseJSON(data)
+	}
+	return json.Unmarshal(data, v)
+}
+
+// Petición atendida por g, para que el codec se lea de su configuración
+func benchRequest(g *GoWay) *http.Request {
+	r := httptest.NewRequest(http.MethodPost, "/", nil)
+	return r.WithContext(context.WithValue(r.Context(), stateKey, &requestState{g: g}))
+}
+
+type discardWriter struct{ h http.Header }
+
+func (w *discardWriter) Header() http.Header         { return w.h }
+func (w *discardWriter) Write(b []byte) (int, error) { return len(b), nil }
+func (w *discardWriter) WriteHeader(int)             {}
+
+var benchCodecs = []struct {
+	name string
+	g    *GoWay
+}{
+	{"encoding-json", &GoWay{}},
+	{"custom", &GoWay{Marshal: fastMarshal, Unmarshal: fastUnmarshal}},
+}
+
+func BenchmarkEncodeJSON(b *testing.B) {
+	u := &benchUser{ID: 42, Name: "Ada Lovelace", Email: "ada@example.com", Active: true}
+	for _, bc := range benchCodecs {
+		b.Run(bc.name, func(b *testing.B) {
+			r, w := benchRequest(bc.g), &discardWriter{h: http.Header{}}
+			b.ReportAllocs()
+			for b.Loop() {
+				if err := encodeJSON(w, r, u); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkUnmarshalJSON(b *testing.B) {
+	data := []byte(`{"id":42,"name":"Ada Lovelace","email":"ada@example.com","active":true}`)
+	for _, bc := range benchCodecs {
+		b.Run(bc.name, func(b *testing.B) {
+			r := benchRequest(bc.g)
+			b.ReportAllocs()
+			for b.Loop() {
+				var u benchUser
+				if err := unmarshalJSON(r, data, &u); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
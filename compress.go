@@ -0,0 +1,235 @@
+package goway
+
+import (
+	"bufio"
+	"compress/gzip"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/andybalholm/brotli"
+)
+
+// Calidad de brotli para compresión al vuelo: buena relación entre tamaño y CPU
+const brotliQuality = 5
+
+var (
+	gzipPool   = sync.Pool{New: func() any { return gzip.NewWriter(io.Discard) }}
+	brotliPool = sync.Pool{New: func() any { return brotli.NewWriterLevel(io.Discard, brotliQuality) }}
+)
+
+// Middleware que comprime la respuesta según Accept-Encoding. Elige br o gzip
+// por el valor q de cada codificación (br si empatan) y si el cliente no
+// acepta ninguna la envía sin comprimir. No comprime tipos que ya vienen
+// comprimidos (imágenes, vídeo, zip...), respuestas con Content-Encoding ni
+// rutas marcadas con NoCompression.
+func CompressMiddleware() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Add("Vary", "Accept-Encoding")
+			encoding := negotiateEncoding(r.Header.Get("Accept-Encoding"))
+			if encoding == "" || r.Method == http.MethodHead {
+				next.ServeHTTP(w, r)
+				return
+			}
+			cw := &compressWriter{ResponseWriter: w, r: r, encoding: encoding}
+			defer cw.Close()
+			next.ServeHTTP(cw, r)
+		})
+	}
+}
+
+// Opción de ruta para que CompressMiddleware no comprima sus respuestas, por
+// ejemplo si ya envía datos comprimidos.
+//
+//	g.GET("/backup", download, goway.NoCompression())
+func NoCompression() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			stateFrom(r).identity = true
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// Codificación preferida por el cliente entre br y gzip ("" para identity)
+func negotiateEncoding(header string) string {
+	q := map[string]float64{}
+	for _, part := range strings.Split(header, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		weight := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if f, err := strconv.ParseFloat(v, 64); err == nil {
+				weight = f
+			}
+		}
+		q[name] = weight
+	}
+	best, bestQ := "", 0.0
+	for _, enc := range []string{"br", "gzip"} {
+		weight, ok := q[enc]
+		if !ok {
+			weight, ok = q["*"]
+		}
+		if ok && weight > bestQ {
+			best, bestQ = enc, weight
+		}
+	}
+	return best
+}
+
+// Tipos que ya están comprimidos y no ganan nada con otra pasada
+func compressible(contentType string) bool {
+	ct, _, _ := strings.Cut(contentType, ";")
+	ct = strings.ToLower(strings.TrimSpace(ct))
+	switch {
+	case strings.HasPrefix(ct, "image/") && ct != "image/svg+xml",
+		strings.HasPrefix(ct, "video/"),
+		strings.HasPrefix(ct, "audio/"),
+		strings.HasPrefix(ct, "font/woff"):
+		return false
+	}
+	switch ct {
+	case "application/zip", "application/gzip", "application/x-gzip",
+		"application/x-brotli", "application/zstd", "application/x-7z-compressed",
+		"application/x-rar-compressed", "application/pdf", "application/octet-stream":
+		return false
+	}
+	return true
+}
+
+// ResponseWriter que decide si comprimir al enviar las cabeceras, cuando ya
+// se conoce el Content-Type y el status. Si WriteHeader llega sin
+// Content-Type el status se retiene hasta la primera escritura, para detectar
+// el tipo sobre el cuerpo sin comprimir; sin tipo conocido no se comprime.
+type compressWriter struct {
+	http.ResponseWriter
+	r        *http.Request
+	encoding string
+	enc      io.WriteCloser
+	decided  bool
+	pending  int // Status retenido hasta la primera escritura
+}
+
+func (w *compressWriter) decide(code int, b []byte) {
+	if w.decided {
+		return
+	}
+	w.decided = true
+	h := w.Header()
+	if code < 200 || code == http.StatusNoContent || code == http.StatusNotModified || h.Get("Content-Encoding") != "" {
+		return
+	}
+	if stateFrom(w.r).identity {
+		return
+	}
+	ct := h.Get("Content-Type")
+	if ct == "" && b != nil {
+		// Detectar el tipo sobre el cuerpo original, no sobre los bytes comprimidos
+		ct = http.DetectContentType(b)
+		h.Set("Content-Type", ct)
+	}
+	if ct == "" || !compressible(ct) {
+		return
+	}
+	h.Del("Content-Length")
+	h.Set("Content-Encoding", w.encoding)
+	switch w.encoding {
+	case "br":
+		bw := brotliPool.Get().(*brotli.Writer)
+		bw.Reset(w.ResponseWriter)
+		w.enc = bw
+	default:
+		gw := gzipPool.Get().(*gzip.Writer)
+		gw.Reset(w.ResponseWriter)
+		w.enc = gw
+	}
+}
+
+func (w *compressWriter) WriteHeader(code int) {
+	if w.pending != 0 {
+		return
+	}
+	if !w.decided && code >= 200 && w.Header().Get("Content-Type") == "" {
+		w.pending = code
+		return
+	}
+	if code >= 200 || code == http.StatusSwitchingProtocols {
+		w.decide(code, nil)
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+// Enviar el status retenido, habiendo decidido con b (nil si no hay cuerpo)
+func (w *compressWriter) sendPending(b []byte) {
+	code := w.pending
+	w.pending = 0
+	w.decide(code, b)
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *compressWriter) Write(b []byte) (int, error) {
+	if w.pending != 0 {
+		w.sendPending(b)
+	}
+	if !w.decided {
+		w.decide(http.StatusOK, b)
+	}
+	if w.enc == nil {
+		return w.ResponseWriter.Write(b)
+	}
+	return w.enc.Write(b)
+}
+
+func (w *compressWriter) Flush() {
+	if w.pending != 0 {
+		w.sendPending(nil)
+	}
+	if !w.decided {
+		w.decide(http.StatusOK, nil)
+	}
+	if f, ok := w.enc.(interface{ Flush() error }); ok {
+		f.Flush()
+	}
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Terminar el flujo comprimido y devolver el codificador a su pool
+func (w *compressWriter) Close() error {
+	if w.pending != 0 {
+		w.sendPending(nil)
+	}
+	if w.enc == nil {
+		return nil
+	}
+	err := w.enc.Close()
+	switch enc := w.enc.(type) {
+	case *brotli.Writer:
+		enc.Reset(io.Discard)
+		brotliPool.Put(enc)
+	case *gzip.Writer:
+		enc.Reset(io.Discard)
+		gzipPool.Put(enc)
+	}
+	w.enc = nil
+	return err
+}
+
+func (w *compressWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func (w *compressWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	// La conexión ya no es HTTP: no hay nada que comprimir al cerrar
+	w.decided = true
+	return hijack(w.ResponseWriter)
+}
@@ -0,0 +1,93 @@
+package goway
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestNoCompressionSkipsMarkedRoute(t *testing.T) {
+	body := strings.Repeat("compressible text ", 100)
+	send := func(c *GoWayContext) { c.Data(http.StatusOK, "text/plain", []byte(body)) }
+	g := NewGoWay()
+	g.Use(CompressMiddleware())
+	g.GET("/plain", send)
+	g.GET("/raw", send, NoCompression())
+
+	get := func(path string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, path, nil)
+		r.Header.Set("Accept-Encoding", "gzip")
+		return g.TestRequest(r)
+	}
+
+	rec := get("/plain")
+	if enc := rec.Header().Get("Content-Encoding"); enc != "gzip" {
+		t.Fatalf("unmarked route Content-Encoding = %q, want gzip", enc)
+	}
+	zr, err := gzip.NewReader(rec.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := io.ReadAll(zr); string(got) != body {
+		t.Errorf("decompressed body does not match")
+	}
+
+	rec = get("/raw")
+	if enc := rec.Header().Get("Content-Encoding"); enc != "" {
+		t.Errorf("marked route Content-Encoding = %q, want none", enc)
+	}
+	if rec.Body.String() != body {
+		t.Errorf("marked route body was modified")
+	}
+}
+
+func TestCompressDetectsTypeAfterWriteHeader(t *testing.T) {
+	text := strings.Repeat("<p>compressible text</p>", 100)
+	var gz strings.Builder
+	zw := gzip.NewWriter(&gz)
+	zw.Write([]byte(text))
+	zw.Close()
+
+	g := NewGoWay()
+	g.Use(CompressMiddleware())
+	g.GET("/html", func(c *GoWayContext) {
+		c.w.WriteHeader(http.StatusOK)
+		c.w.Write([]byte(text))
+	})
+	g.GET("/archive", func(c *GoWayContext) {
+		c.w.WriteHeader(http.StatusOK)
+		c.w.Write([]byte(gz.String()))
+	})
+	get := func(path string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, path, nil)
+		r.Header.Set("Accept-Encoding", "gzip")
+		return g.TestRequest(r)
+	}
+
+	rec := get("/html")
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/html") {
+		t.Errorf("Content-Type = %q, want it detected on the uncompressed body", ct)
+	}
+	if enc := rec.Header().Get("Content-Encoding"); enc != "gzip" {
+		t.Fatalf("Content-Encoding = %q, want gzip", enc)
+	}
+	zr, err := gzip.NewReader(rec.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := io.ReadAll(zr); string(got) != text {
+		t.Errorf("decompressed body does not match")
+	}
+
+	// Un cuerpo ya comprimido no se comprime otra vez
+	rec = get("/archive")
+	if enc := rec.Header().Get("Content-Encoding"); enc != "" {
+		t.Errorf("Content-Encoding = %q for a gzip body, want none", enc)
+	}
+	if rec.Body.String() != gz.String() {
+		t.Errorf("gzip body was modified")
+	}
+}
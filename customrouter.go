@@ -0,0 +1,120 @@
+package goway
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Algoritmo de enrutado alternativo para SetRouter. Match devuelve el
+// manejador para el método y path, con los parámetros del path, u ok=false
+// si no hay ninguno.
+type Router interface {
+	Match(method, path string) (handler http.Handler, params map[string]string, ok bool)
+}
+
+// Sustituir el árbol de rutas por un router propio. Las peticiones siguen
+// pasando por los middlewares globales; después se resuelven solo con
+// router, sin mirar las rutas registradas con Handle, GET, etc. Los
+// parámetros que devuelve quedan disponibles con PathParam. Sin coincidencia
+// se usa el manejador de 404 (SPAFallback) o un 404. Con nil se vuelve al
+// árbol de rutas.
+func (g *GoWay) SetRouter(router Router) {
+	g.router = router
+}
+
+// Router que resuelve las peticiones: el de SetRouter o, si no hay, el
+// árbol de rutas registradas. Un router propio puede guardarlo antes de
+// SetRouter y delegar en él lo que no resuelva.
+func (g *GoWay) Router() Router {
+	if g.router != nil {
+		return g.router
+	}
+	return treeRouter{g}
+}
+
+// Router por defecto, con las rutas registradas en GoWay y sus grupos. Match
+// solo consulta las rutas sin Host; al atender una petición se usa además el
+// árbol de su Host, HEAD sobre las rutas GET y la respuesta 405.
+type treeRouter struct {
+	g *GoWay
+}
+
+func (t treeRouter) Match(method, path string) (http.Handler, map[string]string, bool) {
+	rt, params, _ := t.g.tree.lookup(method, path)
+	if rt == nil {
+		return nil, nil, false
+	}
+	values := make(map[string]string, len(params))
+	for _, p := range params {
+		values[p.key] = p.value
+	}
+	return rt.h, values, true
+}
+
+// Resolver la petición en el árbol de su Host. head indica que es un HEAD
+// atendido por la ruta GET; sin ruta devuelve los métodos que sí tiene el path.
+// exact es falso si no hay ruta o la ruta es un comodín que ha capturado parte
+// del path.
+func (t treeRouter) resolve(r *http.Request) (rt *route, params []pathParam, allowed []string, head, exact bool) {
+	tree, hostParams := t.g.treeFor(r.Host)
+	rt, params, allowed, exact = tree.match(r.Method, r.URL.Path)
+	if rt == nil && r.Method == http.MethodHead {
+		// Las rutas GET también responden a HEAD, sin cuerpo. Una ruta HEAD
+		// explícita tiene prioridad.
+		if rt, params, _, exact = tree.match(http.MethodGet, r.URL.Path); rt != nil {
+			head = true
+		}
+	}
+	if rt == nil {
+		return nil, nil, allowed, false, false
+	}
+	return rt, append(hostParams, params...), nil, head, exact
+}
+
+// Para un path sin barra final cuya versión con barra es exactamente una ruta
+// ("/static" con "/static/" registrado) devolver a dónde redirigir, como hace
+// http.ServeMux. Solo se consulta si el path no tiene ya una ruta exacta: un
+// "/" registrado no evita la redirección.
+func (t treeRouter) slashRedirect(r *http.Request) (string, bool) {
+	tree, _ := t.g.treeFor(r.Host)
+	return slashTarget(tree, r)
+}
+
+func slashTarget(tree *node, r *http.Request) (string, bool) {
+	p := r.URL.Path
+	if strings.HasSuffix(p, "/") || strings.HasPrefix(p, "//") {
+		return "", false
+	}
+	rt, _, _, exact := tree.match(r.Method, p+"/")
+	if rt == nil && r.Method == http.MethodHead {
+		rt, _, _, exact = tree.match(http.MethodGet, p+"/")
+	}
+	if rt == nil || !exact {
+		return "", false
+	}
+	u := url.URL{Path: p + "/", RawQuery: r.URL.RawQuery}
+	return u.String(), true
+}
+
+func (g *GoWay) dispatchCustom(w http.ResponseWriter, r *http.Request) {
+	h, params, ok := g.router.Match(r.Method, r.URL.Path)
+	if !ok || h == nil {
+		stateFrom(r).unrouted = true
+		g.serveNotFound(w, r)
+		return
+	}
+	for k, v := range params {
+		r.SetPathValue(k, v)
+	}
+	h.ServeHTTP(w, r)
+}
+
+// Responder a una petición sin ruta con el manejador de 404 o un 404
+func (g *GoWay) serveNotFound(w http.ResponseWriter, r *http.Request) {
+	if g.notFound != nil {
+		g.notFound(NewGoWayContext(w, r))
+		return
+	}
+	http.NotFound(w, r)
+}
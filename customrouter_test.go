@@ -0,0 +1,37 @@
+package goway
+
+import (
+	"net/http"
+	"testing"
+)
+
+// Router que atiende /health y delega el resto en el de por defecto
+type healthRouter struct{ fallback Router }
+
+func (h healthRouter) Match(method, path string) (http.Handler, map[string]string, bool) {
+	if path == "/health" {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNoContent)
+		}), nil, true
+	}
+	return h.fallback.Match(method, path)
+}
+
+func TestCustomRouterDelegatesToDefault(t *testing.T) {
+	g := NewGoWay()
+	g.GET("/users/:id", func(c *GoWayContext) {
+		c.JSON(http.StatusOK, c.PathParam("id"))
+	})
+	g.SetRouter(healthRouter{fallback: g.Router()})
+
+	if rec := g.Test(http.MethodGet, "/health", nil); rec.Code != http.StatusNoContent {
+		t.Errorf("/health status = %d, want %d", rec.Code, http.StatusNoContent)
+	}
+	rec := g.Test(http.MethodGet, "/users/42", nil)
+	if rec.Code != http.StatusOK || rec.Body.String() != "\"42\"\n" {
+		t.Errorf("/users/42 = %d %q, want 200 \"42\"", rec.Code, rec.Body.String())
+	}
+	if rec := g.Test(http.MethodGet, "/missing", nil); rec.Code != http.StatusNotFound {
+		t.Errorf("/missing status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+}
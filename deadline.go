@@ -0,0 +1,115 @@
+package goway
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"net/http"
+	"time"
+)
+
+// Middleware contra clientes lentos (slowloris): el cliente tiene como mucho
+// readTimeout entre lectura y lectura del cuerpo, y writeTimeout para aceptar
+// cada escritura de la respuesta. Los plazos se renuevan con cada lectura o
+// escritura, así que una subida o descarga larga pero activa no se corta. Si
+// el cliente se queda parado más tiempo se cierra la conexión. Un plazo a 0 no
+// se aplica. Complementa los timeouts de http.Server, que son para toda la
+// petición: los plazos renovados no pasan de ReadTimeout y WriteTimeout, y al
+// terminar se dejan los del servidor.
+func SlowClientMiddleware(readTimeout, writeTimeout time.Duration) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			rc := http.NewResponseController(w)
+			readLimit, writeLimit := serverDeadlines(r)
+			body := readTimeout > 0 && r.Body != nil && r.Body != http.NoBody
+			if body {
+				rc.SetReadDeadline(renewDeadline(readTimeout, readLimit))
+				r.Body = &deadlineReader{ReadCloser: r.Body, rc: rc, timeout: readTimeout, limit: readLimit}
+			}
+			if writeTimeout > 0 {
+				w = &deadlineWriter{ResponseWriter: w, rc: rc, timeout: writeTimeout, limit: writeLimit}
+			}
+			// Devolver los plazos del servidor al terminar: lo que quede de la
+			// respuesta sigue sujeto a su WriteTimeout
+			defer func() {
+				if body {
+					rc.SetReadDeadline(readLimit)
+				}
+				if writeTimeout > 0 {
+					rc.SetWriteDeadline(writeLimit)
+				}
+			}()
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// Plazos que pone http.Server por ReadTimeout y WriteTimeout, contados desde
+// ahora; cero los que no tiene
+func serverDeadlines(r *http.Request) (read, write time.Time) {
+	srv, _ := r.Context().Value(http.ServerContextKey).(*http.Server)
+	if srv == nil {
+		return read, write
+	}
+	now := time.Now()
+	if srv.ReadTimeout > 0 {
+		read = now.Add(srv.ReadTimeout)
+	}
+	if srv.WriteTimeout > 0 {
+		write = now.Add(srv.WriteTimeout)
+	}
+	return read, write
+}
+
+// Plazo renovado sin pasar de limit (cero si no hay límite)
+func renewDeadline(timeout time.Duration, limit time.Time) time.Time {
+	d := time.Now().Add(timeout)
+	if !limit.IsZero() && d.After(limit) {
+		return limit
+	}
+	return d
+}
+
+// Cuerpo que renueva el plazo de lectura tras cada lectura
+type deadlineReader struct {
+	io.ReadCloser
+	rc      *http.ResponseController
+	timeout time.Duration
+	limit   time.Time
+}
+
+func (b *deadlineReader) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if n > 0 {
+		b.rc.SetReadDeadline(renewDeadline(b.timeout, b.limit))
+	}
+	return n, err
+}
+
+// ResponseWriter que da al cliente timeout para aceptar cada escritura
+type deadlineWriter struct {
+	http.ResponseWriter
+	rc      *http.ResponseController
+	timeout time.Duration
+	limit   time.Time
+}
+
+func (w *deadlineWriter) Write(b []byte) (int, error) {
+	w.rc.SetWriteDeadline(renewDeadline(w.timeout, w.limit))
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *deadlineWriter) Flush() {
+	w.rc.SetWriteDeadline(renewDeadline(w.timeout, w.limit))
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (w *deadlineWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func (w *deadlineWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return hijack(w.ResponseWriter)
+}
@@ -0,0 +1,50 @@
+package goway
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// Servidor con WriteTimeout cuyo manejador escribe después de vencer
+func writeTimeoutServer(h http.Handler) *httptest.Server {
+	srv := httptest.NewUnstartedServer(h)
+	srv.Config.WriteTimeout = 100 * time.Millisecond
+	srv.Start()
+	return srv
+}
+
+func TestSlowClientKeepsServerWriteTimeout(t *testing.T) {
+	slow := SlowClientMiddleware(0, time.Second)
+	srv := writeTimeoutServer(slow(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+		w.Write([]byte("late"))
+	})))
+	defer srv.Close()
+
+	if resp, err := http.Get(srv.URL); err == nil {
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		t.Fatalf("got %d %q after the server WriteTimeout, want the connection closed", resp.StatusCode, body)
+	}
+}
+
+func TestSlowClientRestoresServerWriteTimeout(t *testing.T) {
+	slow := SlowClientMiddleware(0, time.Second)
+	inner := slow(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	srv := writeTimeoutServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		inner.ServeHTTP(w, r)
+		// Al salir del middleware sigue el plazo del servidor
+		time.Sleep(200 * time.Millisecond)
+		w.Write([]byte("late"))
+	}))
+	defer srv.Close()
+
+	if resp, err := http.Get(srv.URL); err == nil {
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		t.Fatalf("got %d %q after the server WriteTimeout, want the connection closed", resp.StatusCode, body)
+	}
+}
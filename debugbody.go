@@ -0,0 +1,138 @@
+package goway
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"net"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Cabeceras que se ocultan siempre en DebugBodyMiddleware
+var defaultRedactedHeaders = []string{"Authorization", "Cookie", "Set-Cookie"}
+
+// Middleware de depuración que loguea cabeceras y cuerpos de petición y
+// respuesta, hasta maxBytes de cada uno. Los cuerpos multipart o binarios no
+// se loguean. Las cabeceras y campos (JSON o formulario) cuyo nombre esté en
+// redact se sustituyen por [REDACTED]. Con maxBytes <= 0 no hace nada, lo
+// que permite desactivarlo en producción sin tocar el registro de middlewares.
+func DebugBodyMiddleware(maxBytes int, redact ...string) func(http.Handler) http.Handler {
+	if maxBytes <= 0 {
+		return func(next http.Handler) http.Handler { return next }
+	}
+	redactHeaders := make(map[string]bool)
+	for _, name := range append(defaultRedactedHeaders, redact...) {
+		redactHeaders[http.CanonicalHeaderKey(name)] = true
+	}
+	// Se usan expresiones en vez de decodificar porque el cuerpo puede estar truncado
+	var jsonFields, formFields []*regexp.Regexp
+	for _, name := range redact {
+		q := regexp.QuoteMeta(name)
+		jsonFields = append(jsonFields, regexp.MustCompile(`(?i)("`+q+`"\s*:\s*)("(?:[^"\\]|\\.)*"|[^,}\s]+)`))
+		formFields = append(formFields, regexp.MustCompile(`(?i)((?:^|&)`+q+`=)[^&]*`))
+	}
+	redactBody := func(body []byte) string {
+		s := string(body)
+		for _, re := range jsonFields {
+			s = re.ReplaceAllString(s, `$1"[REDACTED]"`)
+		}
+		for _, re := range formFields {
+			s = re.ReplaceAllString(s, `${1}[REDACTED]`)
+		}
+		return s
+	}
+	headers := func(h http.Header) map[string]string {
+		out := make(map[string]string, len(h))
+		for k, v := range h {
+			if redactHeaders[k] {
+				out[k] = "[REDACTED]"
+			} else {
+				out[k] = strings.Join(v, ", ")
+			}
+		}
+		return out
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fields := logrus.Fields{
+				"method":          r.Method,
+				"path":            r.URL.Path,
+				"request_headers": headers(r.Header),
+			}
+			if r.Body != nil && isTextContent(r.Header.Get("Content-Type")) {
+				// Leer solo el principio y devolverlo al cuerpo para el manejador
+				head, _ := io.ReadAll(io.LimitReader(r.Body, int64(maxBytes)))
+				r.Body = struct {
+					io.Reader
+					io.Closer
+				}{io.MultiReader(bytes.NewReader(head), r.Body), r.Body}
+				fields["request_body"] = redactBody(head)
+			}
+
+			cw := &captureWriter{ResponseWriter: w, max: maxBytes, status: http.StatusOK}
+			next.ServeHTTP(cw, r)
+
+			fields["status"] = cw.status
+			fields["response_headers"] = headers(w.Header())
+			if isTextContent(w.Header().Get("Content-Type")) {
+				fields["response_body"] = redactBody(cw.buf.Bytes())
+			}
+			logrus.WithFields(fields).Info("Request/response bodies")
+		})
+	}
+}
+
+// Tipos de contenido que tiene sentido loguear como texto
+func isTextContent(contentType string) bool {
+	ct := strings.ToLower(contentType)
+	if ct == "" || strings.HasPrefix(ct, "multipart/") {
+		return false
+	}
+	for _, t := range []string{"text/", "json", "xml", "x-www-form-urlencoded", "javascript"} {
+		if strings.Contains(ct, t) {
+			return true
+		}
+	}
+	return false
+}
+
+// ResponseWriter que guarda una copia de los primeros max bytes escritos
+type captureWriter struct {
+	http.ResponseWriter
+	buf    bytes.Buffer
+	max    int
+	status int
+}
+
+func (w *captureWriter) WriteHeader(code int) {
+	if code >= 200 || code == http.StatusSwitchingProtocols {
+		w.status = code
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *captureWriter) Write(b []byte) (int, error) {
+	if rest := w.max - w.buf.Len(); rest > 0 {
+		w.buf.Write(b[:min(rest, len(b))])
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *captureWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (w *captureWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func (w *captureWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return hijack(w.ResponseWriter)
+}
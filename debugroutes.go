@@ -0,0 +1,45 @@
+package goway
+
+import (
+	"html/template"
+	"net/http"
+)
+
+// Path de la página con la tabla de rutas, solo disponible con Debug
+const debugRoutesPath = "/debug/routes"
+
+var debugRoutesTemplate = template.Must(template.New("routes").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Routes</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; }
+th, td { padding: 4px 12px; border-bottom: 1px solid #ddd; text-align: left; }
+td.method { font-weight: bold; }
+code { font-size: 0.95em; }
+</style>
+</head>
+<body>
+<h1>Routes ({{len .}})</h1>
+<table>
+<tr><th>Method</th><th>Pattern</th><th>Host</th><th>Name</th><th>Handler</th><th>Middlewares</th></tr>
+{{range .}}<tr><td class="method">{{.Method}}</td><td><code>{{.Pattern}}</code></td><td>{{.Host}}</td><td>{{.Name}}</td><td><code>{{.Handler}}</code></td><td>{{.Middlewares}}</td></tr>
+{{end}}</table>
+</body>
+</html>
+`))
+
+// Servir la tabla de rutas en HTML si Debug está activo y ninguna ruta
+// registrada ocupa /debug/routes. Devuelve false si no la sirvió.
+func (g *GoWay) serveDebugRoutes(w http.ResponseWriter, r *http.Request) bool {
+	if !g.Debug || r.URL.Path != debugRoutesPath || r.Method != http.MethodGet && r.Method != http.MethodHead {
+		return false
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := debugRoutesTemplate.Execute(w, g.RegisteredRoutes()); err != nil {
+		loggerFrom(r).Errorf("goway: rendering routes page: %v", err)
+	}
+	return true
+}
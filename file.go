@@ -0,0 +1,78 @@
+package goway
+
+import (
+	"errors"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Servir un fichero dentro de la respuesta (no como descarga), con el
+// Content-Type según la extensión, Last-Modified y soporte de Range. Si no
+// existe responde 404 y si no se puede leer 403, con el formato de error del
+// servidor. Con FileRoot configurado solo sirve ficheros dentro de él.
+func (c *GoWayContext) File(name string) {
+	if err := c.serveFile(name); err != nil {
+		writeError(c.w, c.r, err)
+	}
+}
+
+func (c *GoWayContext) serveFile(name string) *CustomError {
+	if g := goWayFrom(c.r); g != nil && g.FileRoot != "" {
+		resolved, err := insideRoot(g.FileRoot, name)
+		if err != nil {
+			return err
+		}
+		name = resolved
+	}
+	f, err := os.Open(name)
+	if err != nil {
+		return fileError(c.r, err)
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return fileError(c.r, err)
+	}
+	if info.IsDir() {
+		return NewCustomError("Not Found", http.StatusNotFound)
+	}
+	http.ServeContent(c.w, c.r, info.Name(), info.ModTime(), f)
+	return nil
+}
+
+// Resolver name dentro de root, siguiendo enlaces simbólicos para que no
+// sirvan de salida del directorio
+func insideRoot(root, name string) (string, *CustomError) {
+	if !filepath.IsAbs(name) {
+		name = filepath.Join(root, name)
+	}
+	realRoot, err := filepath.EvalSymlinks(root)
+	if err != nil {
+		return "", NewCustomError("Not Found", http.StatusNotFound)
+	}
+	realName, err := filepath.EvalSymlinks(name)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return "", NewCustomError("Not Found", http.StatusNotFound)
+		}
+		return "", NewCustomError("Forbidden", http.StatusForbidden)
+	}
+	rel, err := filepath.Rel(realRoot, realName)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", NewCustomError("Forbidden", http.StatusForbidden)
+	}
+	return realName, nil
+}
+
+func fileError(r *http.Request, err error) *CustomError {
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		return NewCustomError("Not Found", http.StatusNotFound)
+	case errors.Is(err, fs.ErrPermission):
+		return NewCustomError("Forbidden", http.StatusForbidden)
+	}
+	return internalError(r, err)
+}
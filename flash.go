@@ -0,0 +1,62 @@
+package goway
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+)
+
+// Cookie donde se guardan los mensajes flash hasta la siguiente petición
+const flashCookie = "goway_flash"
+
+// Redirigir a url dejando un mensaje que la siguiente página lee con Flashes
+// (patrón post/redirect/get). Se acumula con los mensajes aún no leídos.
+//
+// Los mensajes viajan en una cookie sin firmar, así que el cliente puede
+// cambiarlos: no deben contener datos sensibles y hay que escaparlos al
+// mostrarlos, como cualquier entrada del usuario.
+func (c *GoWayContext) RedirectWithFlash(status int, url, message string) {
+	messages := append(readFlashes(c.r), message)
+	if data, err := json.Marshal(messages); err == nil {
+		http.SetCookie(c.w, &http.Cookie{
+			Name:     flashCookie,
+			Value:    base64.RawURLEncoding.EncodeToString(data),
+			Path:     "/",
+			HttpOnly: true,
+			SameSite: http.SameSiteLaxMode,
+		})
+	}
+	http.Redirect(c.w, c.r, url, status)
+}
+
+// Mensajes flash pendientes. Se borran al leerlos, así que solo se muestran
+// una vez.
+func (c *GoWayContext) Flashes() []string {
+	messages := readFlashes(c.r)
+	if len(messages) > 0 {
+		http.SetCookie(c.w, &http.Cookie{
+			Name:     flashCookie,
+			Path:     "/",
+			MaxAge:   -1,
+			HttpOnly: true,
+			SameSite: http.SameSiteLaxMode,
+		})
+	}
+	return messages
+}
+
+func readFlashes(r *http.Request) []string {
+	cookie, err := r.Cookie(flashCookie)
+	if err != nil {
+		return nil
+	}
+	data, err := base64.RawURLEncoding.DecodeString(cookie.Value)
+	if err != nil {
+		return nil
+	}
+	var messages []string
+	if json.Unmarshal(data, &messages) != nil {
+		return nil
+	}
+	return messages
+}
@@ -0,0 +1,55 @@
+package goway
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Parsear el formulario (urlencoded o multipart) una sola vez por petición
+func (c *GoWayContext) parseForm() error {
+	if c.r.Form != nil {
+		return nil
+	}
+	if err := c.limitBody(); err != nil {
+		return err
+	}
+	if strings.HasPrefix(c.r.Header.Get("Content-Type"), "multipart/form-data") {
+		return bodyError(c.r.ParseMultipartForm(defaultMultipartMemory))
+	}
+	return bodyError(c.r.ParseForm())
+}
+
+// Valor de un campo del formulario o de la query ("" si no existe)
+func (c *GoWayContext) FormValue(key string) string {
+	if c.parseForm() != nil {
+		return ""
+	}
+	return c.r.Form.Get(key)
+}
+
+// Valor del campo o def si no existe o está vacío
+func (c *GoWayContext) FormValueDefault(key, def string) string {
+	if v := c.FormValue(key); v != "" {
+		return v
+	}
+	return def
+}
+
+// Valor del campo como int, o def si no existe o no es un número
+func (c *GoWayContext) FormValueInt(key string, def int) int {
+	n, err := strconv.Atoi(c.FormValue(key))
+	if err != nil {
+		return def
+	}
+	return n
+}
+
+// Valor del campo como bool (1, t, true, 0, f, false...), o def si no existe
+// o no es válido
+func (c *GoWayContext) FormValueBool(key string, def bool) bool {
+	b, err := strconv.ParseBool(c.FormValue(key))
+	if err != nil {
+		return def
+	}
+	return b
+}
@@ -2,6 +2,11 @@ module github.com/Osmait/goway
 
 go 1.24.0
 
-require github.com/sirupsen/logrus v1.9.3
+require (
+	github.com/andybalholm/brotli v1.2.5
+	github.com/santhosh-tekuri/jsonschema/v5 v5.3.1
+	github.com/sirupsen/logrus v1.9.3
+	golang.org/x/sync v0.19.0
+)
 
 require golang.org/x/sys v0.0.0-20220715151400-c0bba94af5f8 // indirect
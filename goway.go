@@ -2,13 +2,22 @@ package goway
 
 import (
 	"context"
-	"encoding/json"
+	"crypto/tls"
+	"errors"
 	"fmt"
 	"io"
 	"log"
+	"net"
 	"net/http"
+	"runtime/debug"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
 	"time"
 
+	"github.com/santhosh-tekuri/jsonschema/v5"
 	"github.com/sirupsen/logrus"
 )
 
@@ -31,28 +40,237 @@ func NewCustomError(message string, statusCode int) *CustomError {
 // Middleware de manejo de errores mejorado con error personalizado
 func ErrorHandlingMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rw := newResponseWriter(w)
 		defer func() {
 			if err := recover(); err != nil {
+				if err == http.ErrAbortHandler {
+					panic(err)
+				}
+				// Un panic de WorkerPool llega con el valor original y la pila del worker
+				var workerPanic *recoveredPanic
+				if p, ok := err.(*recoveredPanic); ok {
+					err, workerPanic = p.value, p
+				}
+				stackErr := func() error {
+					if workerPanic != nil {
+						return workerPanic
+					}
+					return panicError(err)
+				}
+				recordPanic(r, err)
+
+				// Si la respuesta ya empezó no se puede enviar el error: cortar la conexión
+				if rw.Written() {
+					if _, ok := err.(*CustomError); !ok {
+						reportError(r, stackErr())
+					}
+					log.Printf("Error after response was written (status %d): %v", rw.Status(), err)
+					panic(http.ErrAbortHandler)
+				}
+
 				var customErr *CustomError
 				switch e := err.(type) {
 				case *CustomError:
 					customErr = e
 				default:
-					customErr = NewCustomError("Internal Server Error", http.StatusInternalServerError)
+					perr := stackErr()
+					reportError(r, perr)
+					if handleInternalError(rw, r, err) {
+						log.Printf("Error: %v", perr)
+						stateFrom(r).err = perr
+						return
+					}
+					customErr = internalError(r, perr)
 				}
 
 				// Loguear el error
 				log.Printf("Error: %v", customErr)
+				stateFrom(r).err = customErr
 
 				// Devolver el error al cliente
-				http.Error(w, customErr.Message, customErr.StatusCode)
+				writeError(rw, r, customErr)
 			}
 		}()
 
-		next.ServeHTTP(w, r)
+		next.ServeHTTP(rw, r)
 	})
 }
 
+// Contar el panic y avisar a OnPanic antes de responder
+func recordPanic(r *http.Request, recovered any) {
+	g := goWayFrom(r)
+	if g == nil {
+		return
+	}
+	g.panics.Add(1)
+	if g.OnPanic != nil {
+		g.OnPanic(r, recovered)
+	}
+}
+
+// Panic recuperado, con la pila del momento en que se recuperó
+type recoveredPanic struct {
+	value any
+	stack []byte
+}
+
+func (p *recoveredPanic) Error() string {
+	return fmt.Sprintf("panic: %v", p.value)
+}
+
+func (p *recoveredPanic) Unwrap() error {
+	err, _ := p.value.(error)
+	return err
+}
+
+// Convertir el valor recuperado de un panic en error
+func panicError(recovered any) error {
+	return &recoveredPanic{value: recovered, stack: debug.Stack()}
+}
+
+// Error 500 para un fallo inesperado. En modo Debug incluye el error y, si
+// viene de un panic, la pila; en producción solo un mensaje genérico.
+func internalError(r *http.Request, err error) *CustomError {
+	if g := goWayFrom(r); g != nil && g.Debug {
+		msg := err.Error()
+		var p *recoveredPanic
+		if errors.As(err, &p) {
+			msg += "\n\n" + string(p.stack)
+		}
+		return NewCustomError(msg, http.StatusInternalServerError)
+	}
+	return NewCustomError("Internal Server Error", http.StatusInternalServerError)
+}
+
+// Servicio externo de errores (Sentry, Rollbar...). Recibe los fallos
+// inesperados: panics y errores que no son *CustomError.
+type ErrorReporter interface {
+	Report(ctx context.Context, err error, r *http.Request)
+}
+
+// Enviar el error al ErrorReporter en segundo plano para no retrasar la
+// respuesta. La petición se copia con un contexto que no se cancela al
+// terminar el manejador.
+func reportError(r *http.Request, err error) {
+	g := goWayFrom(r)
+	if g == nil || g.ErrorReporter == nil {
+		return
+	}
+	ctx := context.WithoutCancel(r.Context())
+	req := r.Clone(ctx)
+	go g.ErrorReporter.Report(ctx, err, req)
+}
+
+// Activar o desactivar keep-alive en el servidor en marcha. Al empezar a
+// sacar la instancia del balanceador (connection draining) conviene
+// desactivarlo: cada respuesta cierra su conexión y los clientes vuelven a
+// conectar a otra instancia, en vez de perder la conexión al apagar. Shutdown
+// ya lo desactiva, pero solo al final del drenado. Sin servidor en marcha
+// tiene el mismo efecto que DisableKeepAlives.
+func (g *GoWay) SetKeepAlivesEnabled(enabled bool) {
+	g.DisableKeepAlives = !enabled
+	if srv := g.srv.Load(); srv != nil {
+		srv.SetKeepAlivesEnabled(enabled)
+	}
+}
+
+// Número de panics recuperados desde que arrancó el servidor
+func (g *GoWay) PanicCount() uint64 {
+	return g.panics.Load()
+}
+
+// Número de conexiones que pasaron a otro protocolo (WebSocket...), que se
+// cuentan aparte de las peticiones normales
+func (g *GoWay) UpgradeCount() uint64 {
+	return g.upgrades.Load()
+}
+
+// Sobre común para respuestas JSON
+type Envelope struct {
+	Data  any `json:"data,omitempty"`
+	Meta  any `json:"meta,omitempty"`
+	Error any `json:"error,omitempty"`
+}
+
+// Formato en que se devuelven los errores al cliente
+type ErrorFormat int
+
+const (
+	ErrorFormatText        ErrorFormat = iota // text/plain (por defecto)
+	ErrorFormatProblemJSON                    // application/problem+json (RFC 7807)
+)
+
+// Función que envía un error al cliente
+type ErrorHandlerFunc func(c *GoWayContext, err *CustomError)
+
+// Usar fn para enviar todos los errores (panics recuperados y errores de
+// WithError). Los grupos pueden tener el suyo con Group.SetErrorHandler.
+func (g *GoWay) SetErrorHandler(fn ErrorHandlerFunc) {
+	g.errorHandler = fn
+}
+
+// Función que responde a un panic inesperado con el valor recuperado
+type PanicHandlerFunc func(c *GoWayContext, recovered any)
+
+// Usar fn para responder a los panics inesperados (los que no son un
+// *CustomError), por ejemplo con una página 500 propia. Recibe el valor
+// recuperado; fn decide el status y el formato. Sin él se envía el 500
+// genérico.
+func (g *GoWay) SetInternalErrorHandler(fn PanicHandlerFunc) {
+	g.panicHandler = fn
+}
+
+// Responder a un panic inesperado con SetInternalErrorHandler si hay uno.
+// Devuelve false si no lo hay.
+func handleInternalError(w http.ResponseWriter, r *http.Request, recovered any) bool {
+	g := goWayFrom(r)
+	if g == nil || g.panicHandler == nil {
+		return false
+	}
+	g.panicHandler(NewGoWayContext(w, r), recovered)
+	return true
+}
+
+// Enviar el error con el manejador del grupo de la ruta, el global o, si no
+// hay ninguno, con el formato por defecto
+func writeError(w http.ResponseWriter, r *http.Request, customErr *CustomError) {
+	st := stateFrom(r)
+	handler := st.route.errorHandler()
+	if handler == nil && st.g != nil {
+		handler = st.g.errorHandler
+	}
+	if handler != nil {
+		handler(NewGoWayContext(w, r), customErr)
+		return
+	}
+	renderError(w, r, customErr)
+}
+
+// Escribir el error según el formato configurado en el servidor o, si la
+// ruta declaró Produces, en el tipo negociado con el cliente
+func renderError(w http.ResponseWriter, r *http.Request, customErr *CustomError) {
+	g := goWayFrom(r)
+	if g != nil && g.ErrorFormat == ErrorFormatProblemJSON {
+		writeProblem(w, r, customErr)
+		return
+	}
+	contentType := negotiateType(r, stateFrom(r).produces)
+	if g != nil && g.EnvelopeResponses && contentType == "" {
+		contentType = "application/json"
+	}
+	switch contentType {
+	case "application/problem+json":
+		writeProblem(w, r, customErr)
+	case "application/json":
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		w.WriteHeader(customErr.StatusCode)
+		encodeJSON(w, r, Envelope{Error: customErr.Message})
+	default:
+		http.Error(w, customErr.Message, customErr.StatusCode)
+	}
+}
+
 func LoggerMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		// Crear un logger con logrus
@@ -64,33 +282,372 @@ func LoggerMiddleware(next http.Handler) http.Handler {
 			ForceColors:   true,
 		})
 
-		// Registrar la solicitud recibida
-		logger.Infof("Received request: %s %s", r.Method, r.URL.Path)
+		var threshold time.Duration
+		var fieldKeys []string
+		onlySlow, quiet404 := false, false
+		st := stateFrom(r)
+		if g := st.g; g != nil {
+			fieldKeys = g.LogContextKeys
+			if g.quietPaths[r.URL.Path] {
+				next.ServeHTTP(w, r)
+				return
+			}
+			threshold, onlySlow = g.SlowRequestThreshold, g.LogOnlySlowRequests && g.SlowRequestThreshold > 0
+			quiet404 = g.QuietNotFound
+		}
 
-		// Medir el tiempo de ejecución de la solicitud
-		start := time.Now()
+		// Registrar la solicitud recibida. Con QuietNotFound aún no se sabe si
+		// hay ruta, así que va a debug y queda la línea final.
+		switch {
+		case quiet404:
+			logger.Debugf("Received request: %s %s", r.Method, r.URL.Path)
+		case !onlySlow:
+			logger.Infof("Received request: %s %s", r.Method, r.URL.Path)
+		}
+
+		// Medir el tiempo de ejecución de la solicitud, desde que llegó a GoWay
+		start := st.start
+		if start.IsZero() {
+			start = time.Now()
+		}
 
 		// Llamar al siguiente handler
-		next.ServeHTTP(w, r)
+		rw := newResponseWriter(w)
+		next.ServeHTTP(rw, r)
+
+		// En conexiones tomadas (WebSocket) la duración no dice nada: el
+		// manejador puede seguir con la conexión mucho después
+		if rw.Hijacked() {
+			logger.Infof("Upgraded connection %s %s to %q", r.Method, r.URL.Path, r.Header.Get("Upgrade"))
+			return
+		}
 
 		// Registrar el tiempo que tomó la solicitud
-		logger.Infof("Request %s %s took %v", r.Method, r.URL.Path, time.Since(start))
+		elapsed := time.Since(start)
+		// Añadir los valores guardados con Set por otros middlewares
+		entry := logrus.NewEntry(logger)
+		if len(fieldKeys) > 0 {
+			values := st.values
+			fields := logrus.Fields{}
+			for _, key := range fieldKeys {
+				if v, ok := values[key]; ok {
+					fields[key] = v
+				}
+			}
+			entry = entry.WithFields(fields)
+		}
+		switch {
+		case quiet404 && st.unrouted && rw.Status() == http.StatusNotFound:
+			entry.Debugf("Request %s %s took %v (status %d)", r.Method, r.URL.Path, elapsed, rw.Status())
+		case threshold > 0 && elapsed >= threshold:
+			entry.Warnf("Slow request %s %s took %v (status %d)", r.Method, r.URL.Path, elapsed, rw.Status())
+		case !onlySlow:
+			entry.Infof("Request %s %s took %v (status %d)", r.Method, r.URL.Path, elapsed, rw.Status())
+		}
 	})
 }
 
 // Definición del tipo de manejador
 type GoWayHandlerFunc func(h *GoWayContext)
 
+// Manejador que devuelve un error en vez de hacer panic
+type GoWayErrorHandlerFunc func(c *GoWayContext) error
+
+// Adaptar un manejador que devuelve error. Un *CustomError se envía con su
+// status, un error de binding (*FieldError o JSON mal formado en Body) con
+// 400 según BindErrorFormatter y un *SchemaError con 400 y la lista de
+// fallos; cualquier otro error se loguea y se responde con un 500 genérico.
+// Los panics del manejador se tratan igual que un error devuelto. El error
+// queda disponible para los middlewares a través de ResultFrom.
+func WithError(fn GoWayErrorHandlerFunc) GoWayHandlerFunc {
+	return func(c *GoWayContext) {
+		err := callRecover(fn, c)
+		if err == nil {
+			return
+		}
+		st := stateFrom(c.r)
+		st.err = err
+		var customErr *CustomError
+		var fieldErr *FieldError
+		var bindErr *bindingError
+		var schemaErr *SchemaError
+		switch {
+		case errors.As(err, &customErr):
+		case errors.As(err, &fieldErr), errors.As(err, &bindErr):
+			customErr = bindErrorResponse(c.r, err)
+		case errors.As(err, &schemaErr):
+			if st.rw == nil || !st.rw.Written() {
+				writeSchemaError(c.w, c.r, schemaErr.Violations)
+			}
+			return
+		default:
+			log.Printf("Error: %v", err)
+			reportError(c.r, err)
+			customErr = internalError(c.r, err)
+		}
+		if st.rw != nil && st.rw.Written() {
+			log.Printf("Error after response was written (status %d): %v", st.rw.Status(), err)
+			return
+		}
+		var p *recoveredPanic
+		if errors.As(err, &p) && handleInternalError(c.w, c.r, p.value) {
+			return
+		}
+		writeError(c.w, c.r, customErr)
+	}
+}
+
+// Ejecutar fn convirtiendo un panic en error: un *CustomError se conserva y
+// cualquier otro valor pasa a ser un error inesperado
+func callRecover(fn GoWayErrorHandlerFunc, c *GoWayContext) (err error) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			if rec == http.ErrAbortHandler {
+				panic(rec)
+			}
+			recordPanic(c.r, rec)
+			if e, ok := rec.(*CustomError); ok {
+				err = e
+			} else {
+				err = panicError(rec)
+			}
+		}
+	}()
+	return fn(c)
+}
+
+// Resultado de la petición para middlewares de métricas o trazas
+type HandlerResult struct {
+	Status int    // Status enviado al cliente
+	Err    error  // Error devuelto por el manejador o panic recuperado (nil si no hubo)
+	Route  string // Patrón de la ruta resuelta (vacío si no hubo coincidencia)
+}
+
+// Obtener el resultado de la petición. Tiene sentido después de llamar al
+// siguiente manejador de la cadena.
+func ResultFrom(r *http.Request) HandlerResult {
+	st := stateFrom(r)
+	result := HandlerResult{Err: st.err}
+	if st.route != nil {
+		result.Route = st.route.pattern
+	}
+	if st.rw != nil {
+		result.Status = st.rw.Status()
+	}
+	return result
+}
+
 // GoWay framework
 type GoWay struct {
-	routes      map[string]GoWayHandlerFunc
+	routes      []*route                          // Rutas en orden de registro
+	tree        *node                             // Árbol usado para resolver las peticiones
+	direct      *node                             // Rutas que no pasan por los middlewares globales
+	hosts       []*hostRouter                     // Árboles por host, registrados con Host
+	names       map[string]*route                 // Rutas con nombre, para URL
+	schemas     map[string]*jsonschema.Schema     // Esquemas de cuerpo por "MÉTODO patrón"
+	quietPaths  map[string]bool                   // Paths que LoggerMiddleware no registra
 	middlewares []func(http.Handler) http.Handler // Lista de middlewares
+
+	// Reintentos al hacer bind si el puerto sigue ocupado (EADDRINUSE).
+	// Con 0 no se reintenta.
+	ListenRetries int
+	// Espera antes del primer reintento; se duplica en cada intento.
+	// Por defecto 500ms.
+	ListenRetryDelay time.Duration
+
+	// Modo desarrollo: los errores inesperados muestran en la respuesta el
+	// error real y la pila. En producción (false) solo un 500 genérico. Los
+	// mensajes de CustomError se muestran siempre. También activa la página
+	// /debug/routes con la tabla de rutas.
+	Debug bool
+
+	// Formato de las respuestas de error de la recuperación de panics
+	ErrorFormat ErrorFormat
+
+	// Anidamiento máximo de objetos/arrays al leer JSON con Body. Con 0 se
+	// usa DefaultMaxJSONDepth; un valor negativo desactiva el límite.
+	MaxJSONDepth int
+
+	// Cabecera con la IP real del cliente que pone la plataforma (CDN, PaaS),
+	// por ejemplo PlatformCloudflare. Tiene prioridad sobre X-Forwarded-For.
+	// Solo se cree si la conexión viene de TrustedProxies, que debe incluir
+	// las redes de la plataforma.
+	TrustedPlatform string
+
+	// Redes (CIDR, o IPs sueltas) de los proxies cuyas cabeceras
+	// X-Forwarded-For, X-Real-IP y X-Forwarded-Proto se creen. Si la conexión
+	// viene de otra dirección se usa RemoteAddr. Vacío solo confía en
+	// loopback. Se lee al arrancar el servidor.
+	TrustedProxies []string
+
+	// Envolver las respuestas de JSON como {"data": ...} y los errores como
+	// {"error": ...}. JSONRaw permite saltárselo en respuestas concretas.
+	EnvelopeResponses bool
+
+	// Las peticiones que tarden al menos esto se loguean como warning. Con 0
+	// todas se loguean igual.
+	SlowRequestThreshold time.Duration
+	// Con SlowRequestThreshold activo, no loguear las peticiones rápidas
+	LogOnlySlowRequests bool
+	// Loguear a nivel debug los 404 de paths sin ninguna ruta, para no
+	// llenar el log con el tráfico de bots; los 404 que devuelve un manejador
+	// se loguean siempre. La línea "Received request" de cada petición pasa
+	// también a debug, porque se escribe antes de resolver la ruta.
+	QuietNotFound bool
+	// Medir el tiempo de cada middleware global, disponible con
+	// MiddlewareTimings. Añade coste a cada petición: solo para depurar. Se
+	// tiene en cuenta al construir la cadena, así que hay que activarlo antes
+	// de la primera petición.
+	ProfileMiddlewares bool
+	// Claves guardadas con Set (tenant, usuario, request id...) que
+	// LoggerMiddleware añade como campos al log de cada petición. Las que no
+	// tengan valor se omiten.
+	LogContextKeys []string
+
+	// Funciones JSON usadas por JSON, Body y las respuestas de error, para
+	// cambiar encoding/json por jsoniter, goccy/go-json, etc. Si son nil se
+	// usa encoding/json.
+	Marshal   func(v any) ([]byte, error)
+	Unmarshal func(data []byte, v any) error
+
+	// Tamaño máximo en bytes del cuerpo que leen Body y BindForm. Con 0 no
+	// hay límite.
+	MaxBodySize int64
+
+	// Convertir los errores de binding (*FieldError de BindForm, BindQuery,
+	// BindHeader... o JSON no válido en Body) que devuelve un manejador con
+	// WithError en la respuesta a enviar. Si es nil, o devuelve nil, se
+	// responde 400 con un mensaje que indica el campo.
+	BindErrorFormatter func(err error) *CustomError
+
+	// Directorio del que File puede servir ficheros. Los paths relativos se
+	// resuelven desde aquí y los que salen de él se rechazan con 403. Vacío
+	// no pone restricciones.
+	FileRoot string
+
+	// Logger para los errores internos del framework. Si es nil se usa el
+	// logger estándar de logrus.
+	Logger logrus.FieldLogger
+
+	// Qué hacer al registrar dos veces el mismo método y path
+	DuplicateRoutes DuplicateRoutePolicy
+
+	// Si es true, EnablePProf registra los endpoints fuera de los
+	// middlewares globales (log, recuperación de panics...); solo pasan por
+	// los que recibe. Se lee al llamar a EnablePProf.
+	PProfBypassMiddlewares bool
+
+	// Tiempo máximo para que terminen las peticiones en curso al apagar.
+	// Por defecto 5s.
+	ShutdownTimeout time.Duration
+
+	// Tiempo que una conexión keep-alive puede quedar sin peticiones antes de
+	// cerrarla. Detrás de un balanceador debe ser mayor que el idle timeout
+	// del balanceador: si el servidor cierra primero, el balanceador puede
+	// reutilizar una conexión ya cerrada y el cliente ve "connection reset" o
+	// un 502. Con 0 se usa el comportamiento de net/http (sin límite).
+	IdleTimeout time.Duration
+	// Tiempo máximo para leer las cabeceras de cada petición; protege contra
+	// clientes que las envían muy despacio. Con 0 no hay límite.
+	ReadHeaderTimeout time.Duration
+	// Cerrar la conexión después de cada respuesta, para despliegues donde el
+	// balanceador no gestiona bien las conexiones persistentes.
+	DisableKeepAlives bool
+
+	// Recibe los errores inesperados para enviarlos a un servicio externo
+	ErrorReporter ErrorReporter
+
+	// Se llama cada vez que se recupera un panic de un manejador, antes de
+	// enviar la respuesta de error. Útil para métricas o alertas.
+	OnPanic func(r *http.Request, recovered any)
+
+	// Se llama con la dirección real (resuelta si se usó ":0") en cuanto el
+	// listener acepta conexiones
+	OnStart func(addr string)
+
+	baseCtx      context.Context  // Contexto base con valores compartidos (DB, config...)
+	notFound     GoWayHandlerFunc // Manejador para peticiones sin ruta (nil = 404)
+	errorHandler ErrorHandlerFunc // Manejador global de errores (nil = formato por defecto)
+	panicHandler PanicHandlerFunc // Respuesta a panics inesperados (nil = 500 genérico)
+	router       Router           // Router propio de SetRouter (nil = árbol de rutas)
+	pool         *WorkerPool      // Pool de SetWorkerPool (nil = goroutine por petición)
+	afterHooks   []AfterResponseFunc
+	enrichers    []func(ctx context.Context, r *http.Request) context.Context
+	headers      http.Header                     // Cabeceras por defecto (valor nil = no enviarla)
+	addr         atomic.Value                    // Dirección real del listener
+	conns        atomic.Int64                    // Conexiones abiertas
+	panics       atomic.Uint64                   // Panics recuperados
+	upgrades     atomic.Uint64                   // Conexiones tomadas con Hijack
+	srv          atomic.Pointer[http.Server]     // Servidor en marcha (nil si no hay)
+	cert         atomic.Pointer[tls.Certificate] // Certificado actual de RunTLS
+	recent       atomic.Pointer[requestRing]     // Últimas peticiones de RequestRecorderMiddleware
+	chain        atomic.Pointer[http.Handler]    // Cadena de middlewares ya construida
+	proxies      atomic.Pointer[[]*net.IPNet]    // TrustedProxies ya interpretados
+}
+
+// Política ante rutas duplicadas
+type DuplicateRoutePolicy int
+
+const (
+	DuplicateRoutePanic DuplicateRoutePolicy = iota // panic al registrar (por defecto)
+	DuplicateRouteWarn                              // avisar en el log y quedarse con la última
+)
+
+type contextKey int
+
+const (
+	stateKey contextKey = iota
+	localeKey
+)
+
+// Estado de la petición compartido entre middlewares, router y manejador
+type requestState struct {
+	g        *GoWay
+	rw       *responseWriter
+	route    *route         // Ruta resuelta (nil si no hubo coincidencia)
+	produces []string       // Tipos declarados con Produces
+	err      error          // Error devuelto por el manejador o recuperado de un panic
+	values   map[string]any // Valores guardados con Set
+	cleanups []func()       // Funciones registradas con OnDisconnect
+	start    time.Time      // Momento en que llegó la petición
+	timings  *timingProfile // Tiempos de los middlewares con ProfileMiddlewares
+	identity bool           // Ruta marcada con NoCompression (sin comprimir)
+	skipPool bool           // Ruta marcada con SkipWorkerPool
+	unrouted bool           // Ninguna ruta coincide con el path, con ningún método
+}
+
+// Ejecutar las funciones de OnDisconnect que no se hayan disparado aún
+func (st *requestState) cleanup() {
+	for _, fn := range st.cleanups {
+		fn()
+	}
+}
+
+// Obtener el estado de la petición; fuera de GoWay devuelve uno vacío
+func stateFrom(r *http.Request) *requestState {
+	if st, ok := r.Context().Value(stateKey).(*requestState); ok {
+		return st
+	}
+	return &requestState{}
+}
+
+// Logger configurado en el servidor de la petición
+func loggerFrom(r *http.Request) logrus.FieldLogger {
+	if g := goWayFrom(r); g != nil && g.Logger != nil {
+		return g.Logger
+	}
+	return logrus.StandardLogger()
+}
+
+// Obtener el servidor que atiende la petición (nil fuera de GoWay)
+func goWayFrom(r *http.Request) *GoWay {
+	return stateFrom(r).g
 }
 
 // Constructor
 func NewGoWay() *GoWay {
 	server := &GoWay{
-		routes: make(map[string]GoWayHandlerFunc),
+		tree: newNode(),
 	}
 	server.Use(LoggerMiddleware)
 	server.Use(ErrorHandlingMiddleware)
@@ -99,58 +656,430 @@ func NewGoWay() *GoWay {
 
 // Método para ejecutar el servidor
 func (g *GoWay) Run(addr string, ctx context.Context) error {
-	mux := http.NewServeMux()
-
-	for pattern, handler := range g.routes {
-		logrus.Infof("Registered route: %s", pattern) // Log de la ruta registrada
-		// Crear el manejador para la ruta actual
-		handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			// Crear contexto para manejar la petición
-			ctx := NewGoWayContext(w, r)
-			handler(ctx)
-		})
+	return g.run(ctx, addr, nil)
+}
 
-		// Aplicar la cadena de middlewares y luego el manejador de la ruta
-		mux.Handle(pattern, ChainMiddlewares(g.middlewares, handler))
+// Ejecutar el servidor, con TLS si tlsConfig no es nil
+func (g *GoWay) run(ctx context.Context, addr string, tlsConfig *tls.Config) error {
+	for _, rt := range g.routes {
+		logrus.Infof("Registered route: %s %s", rt.method, rt.pattern) // Log de la ruta registrada
+	}
+	if err := g.parseTrustedProxies(); err != nil {
+		return err
 	}
+
 	srv := &http.Server{
 		Addr:    addr,
-		Handler: mux,
+		Handler: g,
+		BaseContext: func(net.Listener) context.Context {
+			return g.baseContext()
+		},
+		ConnState: g.trackConn,
+		TLSConfig: tlsConfig,
+
+		IdleTimeout:       g.IdleTimeout,
+		ReadHeaderTimeout: g.ReadHeaderTimeout,
+	}
+	srv.SetKeepAlivesEnabled(!g.DisableKeepAlives)
+	g.srv.Store(srv)
+	defer g.srv.Store(nil)
+
+	ln, err := g.listen(ctx, addr)
+	if err != nil {
+		return err
+	}
+	if tlsConfig != nil {
+		ln = tls.NewListener(ln, tlsConfig)
+	}
+	g.addr.Store(ln.Addr().String())
+	if g.OnStart != nil {
+		g.OnStart(ln.Addr().String())
 	}
 
 	// Ejecutar el servidor en una goroutine
+	serveErr := make(chan error, 1)
 	go func() {
-		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Fatal("server shut down", err)
+		if err := srv.Serve(ln); err != nil && err != http.ErrServerClosed {
+			serveErr <- err
 		}
 	}()
 
-	// Esperar la señal de terminación
-	<-ctx.Done()
+	// Esperar la señal de terminación o un error del servidor
+	select {
+	case err := <-serveErr:
+		return err
+	case <-ctx.Done():
+	}
 	// Crear contexto con timeout para apagar el servidor
-	ctxShutDown, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	timeout := g.ShutdownTimeout
+	if timeout <= 0 {
+		timeout = 5 * time.Second
+	}
+	ctxShutDown, cancel := context.WithTimeout(context.Background(), timeout)
 	defer cancel()
 
-	log.Println("Shutting down server...")
+	log.Printf("Shutting down server... (%d active connections)", g.ActiveConnections())
+
+	done := make(chan error, 1)
+	go func() { done <- srv.Shutdown(ctxShutDown) }()
+
+	// Informar cada segundo de las conexiones que siguen abiertas
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	deadline := time.Now().Add(timeout)
+	for {
+		select {
+		case err := <-done:
+			return err
+		case <-ticker.C:
+			log.Printf("Waiting for %d active connections (%v left)", g.ActiveConnections(), time.Until(deadline).Round(time.Second))
+		}
+	}
+}
+
+// Llevar la cuenta de conexiones abiertas a partir de los cambios de estado
+func (g *GoWay) trackConn(_ net.Conn, state http.ConnState) {
+	switch state {
+	case http.StateNew:
+		g.conns.Add(1)
+	case http.StateClosed, http.StateHijacked:
+		g.conns.Add(-1)
+	}
+}
+
+// Número de conexiones abiertas en este momento, incluidas las keep-alive inactivas
+func (g *GoWay) ActiveConnections() int {
+	return int(g.conns.Load())
+}
+
+// Registrar una función que añade valores (trace ID, tenant...) al contexto de
+// cada petición antes de los middlewares y el manejador. Se ejecutan en orden
+// de registro y cada una recibe el contexto devuelto por la anterior.
+func (g *GoWay) EnrichContext(fn func(ctx context.Context, r *http.Request) context.Context) {
+	g.enrichers = append(g.enrichers, fn)
+}
+
+// Preparar el writer y el contexto de la petición antes de la cadena de middlewares
+func (g *GoWay) prepareRequest(w http.ResponseWriter, r *http.Request) (*responseWriter, *http.Request) {
+	rw := newResponseWriter(w)
+	h := rw.Header()
+	for k, v := range g.headers {
+		h[k] = slices.Clone(v)
+	}
+	ctx := context.WithValue(r.Context(), stateKey, &requestState{g: g, rw: rw, start: time.Now()})
+	for _, enrich := range g.enrichers {
+		ctx = enrich(ctx, r)
+	}
+	return rw, r.WithContext(ctx)
+}
+
+// Añadir una cabecera a todas las respuestas. El manejador puede
+// cambiarla o quitarla en una respuesta concreta.
+func (g *GoWay) SetDefaultHeader(key, value string) {
+	if g.headers == nil {
+		g.headers = make(http.Header)
+	}
+	g.headers.Set(key, value)
+}
+
+// No enviar una cabecera que net/http añade por su cuenta, como Date o el
+// Content-Type detectado del cuerpo. Si el manejador la fija se envía igual.
+func (g *GoWay) StripDefaultHeader(key string) {
+	if g.headers == nil {
+		g.headers = make(http.Header)
+	}
+	g.headers[http.CanonicalHeaderKey(key)] = nil
+}
+
+// Dirección en la que escucha el servidor, con el puerto real si se usó ":0".
+// Vacía hasta que Run abre el listener.
+func (g *GoWay) Addr() string {
+	addr, _ := g.addr.Load().(string)
+	return addr
+}
+
+// Definir el contexto base cuyos valores heredan todas las peticiones.
+// Solo se usan sus valores: cancelarlo no cancela las peticiones en curso.
+// Si un valor del contexto de la petición usa la misma clave, gana el de la
+// petición (los middlewares pueden sobrescribir valores base).
+func (g *GoWay) WithBaseContext(ctx context.Context) *GoWay {
+	g.baseCtx = ctx
+	return g
+}
+
+func (g *GoWay) baseContext() context.Context {
+	if g.baseCtx == nil {
+		return context.Background()
+	}
+	return context.WithoutCancel(g.baseCtx)
+}
+
+// Abrir el listener reintentando mientras la dirección siga en uso
+func (g *GoWay) listen(ctx context.Context, addr string) (net.Listener, error) {
+	if addr == "" {
+		addr = ":http"
+	}
+	delay := g.ListenRetryDelay
+	if delay <= 0 {
+		delay = 500 * time.Millisecond
+	}
+	for attempt := 0; ; attempt++ {
+		ln, err := net.Listen("tcp", addr)
+		if err == nil {
+			return ln, nil
+		}
+		if !errors.Is(err, syscall.EADDRINUSE) || attempt >= g.ListenRetries {
+			return nil, err
+		}
+		log.Printf("Address %s in use, retrying in %v (%d/%d)", addr, delay, attempt+1, g.ListenRetries)
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return nil, err
+		}
+		delay *= 2
+	}
+}
+
+// Registrar rutas. Los segmentos ":id" y "{id}" capturan un parámetro,
+// ":id(\d+)" solo lo captura si cumple la expresión y "*path" o "{path...}"
+// capturan el resto del path. Los middlewares opcionales se aplican solo a
+// esta ruta, después de los globales.
+func (g *GoWay) Handle(method, pattern string, handler GoWayHandlerFunc, middlewares ...func(http.Handler) http.Handler) {
+	g.addRoute(newRoute(method, pattern, handler, middlewares))
+}
+
+// Métodos cubiertos por Any
+var anyMethods = []string{
+	http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch,
+	http.MethodDelete, http.MethodOptions, http.MethodHead,
+}
+
+// Registrar el manejador para todos los métodos habituales del path. Una ruta
+// registrada para un método concreto tiene prioridad sobre Any, sin importar
+// el orden de registro.
+func (g *GoWay) Any(pattern string, handler GoWayHandlerFunc, middlewares ...func(http.Handler) http.Handler) {
+	for _, method := range anyMethods {
+		rt := newRoute(method, pattern, handler, middlewares)
+		rt.any = true
+		g.addRoute(rt)
+	}
+}
+
+func newRoute(method, pattern string, handler GoWayHandlerFunc, middlewares []func(http.Handler) http.Handler) *route {
+	rt := &route{method: method, pattern: pattern, handler: handler, middlewares: middlewares}
+	chain := ChainMiddlewares(middlewares, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// El esquema se valida después de los middlewares de la ruta, para no
+		// leer el cuerpo de peticiones que rechazan (autenticación, límites...)
+		if g := goWayFrom(r); g != nil && !g.validateRequest(w, r, rt) {
+			return
+		}
+		// Crear contexto para manejar la petición
+		runHandler(handler, w, r)
+	}))
+	rt.h = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		stateFrom(r).route = rt
+		chain.ServeHTTP(w, r)
+	})
+	return rt
+}
 
-	return srv.Shutdown(ctxShutDown)
+func (g *GoWay) addRoute(rt *route) {
+	tree := g.tree
+	if rt.group != nil && rt.group.host != nil {
+		tree = rt.group.host.tree
+	}
+	leaf := tree.leaf(rt.pattern)
+	if prev := leaf.routes[rt.method]; prev != nil {
+		switch {
+		case prev.any && !rt.any:
+			// La ruta explícita sustituye a la de Any
+		case rt.any && !prev.any:
+			return
+		default:
+			msg := fmt.Sprintf("goway: duplicate route %s %s (conflicts with %s %s)", rt.method, rt.pattern, prev.method, prev.pattern)
+			if g.DuplicateRoutes == DuplicateRoutePanic {
+				panic(msg)
+			}
+			log.Println(msg)
+		}
+		g.removeRoute(prev)
+	}
+	leaf.routes[rt.method] = rt
+	g.routes = append(g.routes, rt)
 }
 
-// Registrar rutas
-func (g *GoWay) Handle(method, pattern string, handler GoWayHandlerFunc) {
-	g.routes[fmt.Sprintf("%s %s", method, pattern)] = handler
+func (g *GoWay) removeRoute(rt *route) {
+	for i, r := range g.routes {
+		if r == rt {
+			g.routes = append(g.routes[:i], g.routes[i+1:]...)
+			return
+		}
+	}
+}
+
+// Resolver la ruta y ejecutar su manejador
+func (g *GoWay) dispatch(w http.ResponseWriter, r *http.Request) {
+	tr, ok := g.Router().(treeRouter)
+	if !ok {
+		g.dispatchCustom(w, r)
+		return
+	}
+	rt, params, allowed, head, exact := tr.resolve(r)
+	if !exact {
+		if target, ok := tr.slashRedirect(r); ok {
+			http.Redirect(w, r, target, http.StatusTemporaryRedirect)
+			return
+		}
+	}
+	if rt == nil {
+		stateFrom(r).unrouted = len(allowed) == 0
+		if g.serveDebugRoutes(w, r) {
+			return
+		}
+		if len(allowed) > 0 {
+			if slices.Contains(allowed, http.MethodGet) && !slices.Contains(allowed, http.MethodHead) {
+				allowed = append(allowed, http.MethodHead)
+				slices.Sort(allowed)
+			}
+			w.Header().Set("Allow", strings.Join(allowed, ", "))
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+		g.serveNotFound(w, r)
+		return
+	}
+	if head {
+		w = &headWriter{w}
+	}
+	for _, p := range params {
+		r.SetPathValue(p.key, p.value)
+	}
+	rt.h.ServeHTTP(w, r)
 }
 
-func (g *GoWay) GET(pattern string, handler GoWayHandlerFunc) {
-	g.Handle("GET", pattern, handler)
+func (g *GoWay) GET(pattern string, handler GoWayHandlerFunc, middlewares ...func(http.Handler) http.Handler) {
+	g.Handle("GET", pattern, handler, middlewares...)
 }
 
-func (g *GoWay) POST(pattern string, handler GoWayHandlerFunc) {
-	g.Handle("POST", pattern, handler)
+func (g *GoWay) POST(pattern string, handler GoWayHandlerFunc, middlewares ...func(http.Handler) http.Handler) {
+	g.Handle("POST", pattern, handler, middlewares...)
 }
 
+// Registrar un middleware global. Se aplica a todas las rutas, también a las
+// registradas antes de llamar a Use: la cadena se construye al atender la
+// primera petición y se reconstruye si cambian los middlewares. Se ejecutan
+// en el orden en que se registran.
 func (g *GoWay) Use(middleware func(http.Handler) http.Handler) {
 	g.middlewares = append(g.middlewares, middleware)
+	g.chain.Store(nil)
+}
+
+// Copia de los middlewares globales registrados, en orden
+func (g *GoWay) Middlewares() []func(http.Handler) http.Handler {
+	return slices.Clone(g.middlewares)
+}
+
+// Quitar todos los middlewares globales, incluidos los de NewGoWay (logger y
+// recuperación de errores). Afecta a las peticiones atendidas a partir de
+// ese momento; los middlewares de ruta y de grupo se mantienen.
+func (g *GoWay) ResetMiddlewares() {
+	g.middlewares = nil
+	g.chain.Store(nil)
+}
+
+// Registrar un middleware que trabaja con GoWayContext en vez de
+// http.Handler. Debe llamar a next para continuar la cadena; si no lo hace la
+// petición termina ahí. Se ejecuta en el mismo orden que los registrados con Use.
+//
+//	g.UseContext(func(c *goway.GoWayContext, next func()) {
+//		c.Set("ip", c.ClientIP())
+//		next()
+//	})
+func (g *GoWay) UseContext(mw func(c *GoWayContext, next func())) {
+	g.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			c := NewGoWayContext(w, r)
+			mw(c, func() { next.ServeHTTP(c.w, c.r) })
+		})
+	})
+}
+
+// Atender una petición con la cadena de middlewares y el router. Permite usar
+// GoWay como http.Handler en otro servidor o en tests con httptest.
+func (g *GoWay) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if g.baseCtx != nil {
+		r = r.WithContext(valuesContext{r.Context(), g.baseCtx})
+	}
+	rw, r := g.prepareRequest(w, r)
+	if len(g.afterHooks) > 0 {
+		defer g.afterResponse(rw, r)
+	}
+	defer stateFrom(r).cleanup()
+	if g.direct != nil && g.serveDirect(rw, r) {
+		return
+	}
+	g.handler().ServeHTTP(rw, r)
+	if rw.Hijacked() {
+		g.upgrades.Add(1)
+	}
+}
+
+// Función que se llama al terminar cada respuesta
+type AfterResponseFunc func(c *GoWayContext, status int, duration time.Duration)
+
+// Registrar una función que se llama después de enviar cada respuesta, con
+// el status final y la duración, por ejemplo para auditoría. Se ejecutan en
+// orden de registro y siempre, incluso si el manejador hizo panic. La
+// respuesta ya está enviada, así que no pueden cambiarla. Los valores
+// guardados con Set siguen disponibles con Get.
+func (g *GoWay) AfterResponse(fn AfterResponseFunc) {
+	g.afterHooks = append(g.afterHooks, fn)
+}
+
+func (g *GoWay) afterResponse(rw *responseWriter, r *http.Request) {
+	c := NewGoWayContext(rw, r)
+	status, duration := rw.Status(), c.Elapsed()
+	for _, fn := range g.afterHooks {
+		func() {
+			// Un hook que falla no impide que se ejecuten los demás
+			defer func() {
+				if rec := recover(); rec != nil {
+					loggerFrom(r).Errorf("goway: AfterResponse hook panicked: %v", rec)
+				}
+			}()
+			fn(c, status, duration)
+		}()
+	}
+}
+
+// Cadena de middlewares y router; se construye en la primera petición y de
+// nuevo si cambian los middlewares
+func (g *GoWay) handler() http.Handler {
+	if h := g.chain.Load(); h != nil {
+		return *h
+	}
+	var h http.Handler
+	if g.ProfileMiddlewares {
+		h = g.timedChain(http.HandlerFunc(g.dispatch))
+	} else {
+		h = ChainMiddlewares(g.middlewares, http.HandlerFunc(g.dispatch))
+	}
+	g.chain.Store(&h)
+	return h
+}
+
+// Contexto que busca los valores primero en la petición y luego en el base
+type valuesContext struct {
+	context.Context
+	base context.Context
+}
+
+func (c valuesContext) Value(key any) any {
+	if v := c.Context.Value(key); v != nil {
+		return v
+	}
+	return c.base.Value(key)
 }
 
 func ChainMiddlewares(middlewares []func(http.Handler) http.Handler, final http.Handler) http.Handler {
@@ -172,26 +1101,206 @@ func NewGoWayContext(w http.ResponseWriter, r *http.Request) *GoWayContext {
 	return &GoWayContext{w, r}
 }
 
+// Contexto de la petición, incluye los valores del contexto base. Se
+// cancela cuando el cliente cierra la conexión, así que conviene pasarlo a
+// las consultas y llamadas que haga el manejador.
+func (c *GoWayContext) Context() context.Context {
+	return c.r.Context()
+}
+
+// Indica si la petición se canceló (el cliente se desconectó o venció el
+// plazo) o ya se respondió con Error, para dejar de trabajar en respuestas
+// que nadie va a leer
+func (c *GoWayContext) IsAborted() bool {
+	if rw := stateFrom(c.r).rw; rw != nil && rw.closed {
+		return true
+	}
+	return c.r.Context().Err() != nil
+}
+
+// Responder con err (con el mismo formato que los errores recuperados) y dar
+// la respuesta por terminada: lo que el manejador escriba después se
+// descarta. Para errores esperados es más claro que panic(NewCustomError(...)).
+//
+//	if user == nil {
+//		c.Error(goway.NewCustomError("user not found", http.StatusNotFound))
+//		return
+//	}
+func (c *GoWayContext) Error(err *CustomError) {
+	st := stateFrom(c.r)
+	if st.rw != nil && st.rw.Written() {
+		log.Printf("Error after response was written (status %d): %v", st.rw.Status(), err)
+	} else {
+		st.err = err
+		writeError(c.w, c.r, err)
+	}
+	if st.rw != nil {
+		st.rw.closed = true
+	}
+}
+
+// Ejecutar fn en otra goroutine protegida como la del manejador: un panic
+// se loguea, cuenta en PanicCount, se avisa a OnPanic y al ErrorReporter, pero
+// no tira el servidor. Si fn necesita el contexto y puede seguir después de
+// la respuesta, debe usar context.WithoutCancel(c.Context()), porque el de la
+// petición se cancela al terminar.
+func (c *GoWayContext) Go(fn func()) {
+	r := c.r
+	go func() {
+		defer func() {
+			if rec := recover(); rec != nil {
+				recordPanic(r, rec)
+				err := panicError(rec)
+				loggerFrom(r).Errorf("goway: panic in goroutine started by %s %s: %v", r.Method, r.URL.Path, err)
+				reportError(r, err)
+			}
+		}()
+		fn()
+	}()
+}
+
+// Tiempo transcurrido desde que llegó la petición, para decidir si queda
+// margen para trabajo opcional. Es el mismo inicio que usa LoggerMiddleware.
+func (c *GoWayContext) Elapsed() time.Duration {
+	start := stateFrom(c.r).start
+	if start.IsZero() {
+		return 0
+	}
+	return time.Since(start)
+}
+
+// Registrar una función de limpieza (cerrar una transacción, liberar un
+// recurso...) que se ejecuta una sola vez: cuando el cliente se desconecta
+// o, si no, al terminar la petición.
+func (c *GoWayContext) OnDisconnect(fn func()) {
+	once := sync.OnceFunc(fn)
+	context.AfterFunc(c.r.Context(), once)
+	st := stateFrom(c.r)
+	st.cleanups = append(st.cleanups, once)
+}
+
+// Guardar un valor para el resto de la petición (middlewares y manejador)
+func (c *GoWayContext) Set(key string, value any) {
+	st := stateFrom(c.r)
+	if st.values == nil {
+		st.values = make(map[string]any)
+	}
+	st.values[key] = value
+}
+
+// Obtener un valor guardado con Set
+func (c *GoWayContext) Get(key string) (any, bool) {
+	v, ok := stateFrom(c.r).values[key]
+	return v, ok
+}
+
+// Obtener parámetro del path
+func (c *GoWayContext) PathParam(key string) string {
+	return c.r.PathValue(key)
+}
+
 // Obtener parámetro de query
 func (c *GoWayContext) QueryParam(key string) string {
 	return c.r.URL.Query().Get(key)
 }
 
-// Leer JSON del cuerpo de la petición
+// Leer JSON del cuerpo de la petición. Si el anidamiento supera MaxJSONDepth
+// devuelve un CustomError 400 y si el cuerpo supera MaxBodySize un 413.
 func (c *GoWayContext) Body(v interface{}) error {
-	body, err := io.ReadAll(c.r.Body)
+	body, err := c.readJSON()
 	if err != nil {
 		return err
 	}
+	return decodeJSONBody(c.r, body, v)
+}
+
+// Leer el cuerpo aplicando MaxBodySize y MaxJSONDepth, sin decodificarlo
+func (c *GoWayContext) readJSON() ([]byte, error) {
+	if err := c.limitBody(); err != nil {
+		return nil, err
+	}
+	body, err := io.ReadAll(c.r.Body)
+	if err != nil {
+		return nil, bodyError(err)
+	}
 	defer c.r.Body.Close()
-	return json.Unmarshal(body, v)
+	if err := checkJSONDepth(body, maxJSONDepth(c.r)); err != nil {
+		return nil, err
+	}
+	return body, nil
 }
 
-// Enviar respuesta JSON
+// Enviar respuesta JSON, envuelta en {"data": ...} si el servidor tiene EnvelopeResponses
 func (c *GoWayContext) JSON(status int, data interface{}) {
+	c.JSONErr(status, data)
+}
+
+// Igual que JSON pero devuelve el error si no se pudo codificar o escribir
+func (c *GoWayContext) JSONErr(status int, data interface{}) error {
+	if g := goWayFrom(c.r); g != nil && g.EnvelopeResponses {
+		data = Envelope{Data: data}
+	}
+	return c.writeJSON(status, data)
+}
+
+// Enviar respuesta JSON tal cual, sin sobre aunque esté activado
+func (c *GoWayContext) JSONRaw(status int, data interface{}) {
+	c.writeJSON(status, data)
+}
+
+// Codificar antes de escribir nada, para poder responder con un 500 si los
+// datos no se pueden convertir a JSON
+func (c *GoWayContext) writeJSON(status int, data interface{}) error {
+	body, err := marshalJSON(c.r, data)
+	if err != nil {
+		loggerFrom(c.r).Errorf("Error encoding JSON response for %s %s: %v", c.r.Method, c.r.URL.Path, err)
+		if rw := stateFrom(c.r).rw; rw == nil || !rw.Written() {
+			writeError(c.w, c.r, internalError(c.r, err))
+		}
+		return err
+	}
 	c.w.Header().Set("Content-Type", "application/json")
 	c.w.WriteHeader(status)
-	json.NewEncoder(c.w).Encode(data)
+	if _, err := c.w.Write(append(body, '\n')); err != nil {
+		loggerFrom(c.r).Errorf("Error writing JSON response for %s %s: %v", c.r.Method, c.r.URL.Path, err)
+		return err
+	}
+	return nil
+}
+
+// Enviar respuesta JSON con sobre {"data": ..., "meta": ...}
+func (c *GoWayContext) Success(status int, data any, meta any) {
+	c.JSONRaw(status, Envelope{Data: data, Meta: meta})
+}
+
+// Escribir datos crudos en la respuesta (útil para long-polling o protocolos propios)
+func (c *GoWayContext) Write(p []byte) (int, error) {
+	return c.w.Write(p)
+}
+
+// Enviar data con el Content-Type indicado
+func (c *GoWayContext) Data(status int, contentType string, data []byte) {
+	c.w.Header().Set("Content-Type", contentType)
+	c.w.WriteHeader(status)
+	c.w.Write(data)
+}
+
+// Enviar data con el Content-Type detectado de sus primeros bytes
+// (http.DetectContentType). Si no se reconoce, o data está vacío, se envía
+// como application/octet-stream. Si se conoce el tipo es mejor usar Data.
+func (c *GoWayContext) DataSniff(status int, data []byte) {
+	contentType := "application/octet-stream"
+	if len(data) > 0 {
+		contentType = http.DetectContentType(data)
+	}
+	c.Data(status, contentType, data)
+}
+
+// Enviar al cliente lo escrito hasta ahora; no hace nada si el writer no soporta flush
+func (c *GoWayContext) Flush() {
+	if f, ok := c.w.(http.Flusher); ok {
+		f.Flush()
+	}
 }
 
 // Obtener un valor del header (simulación de middleware)
@@ -0,0 +1,103 @@
+package goway
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPanicAfterPartialWriteAbortsConnection(t *testing.T) {
+	g := NewGoWay()
+	g.GET("/partial", func(c *GoWayContext) {
+		c.Write([]byte("partial"))
+		c.Flush()
+		panic("boom")
+	})
+	srv := httptest.NewServer(g)
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "/partial")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+	body, err := io.ReadAll(resp.Body)
+	// La conexión se corta a mitad del cuerpo en vez de terminarlo
+	if err == nil {
+		t.Fatalf("body read completed (%q), want connection aborted", body)
+	}
+	if string(body) != "partial" {
+		t.Errorf("body = %q, want %q", body, "partial")
+	}
+	if strings.Contains(string(body), "Internal Server Error") {
+		t.Errorf("error response appended to partial body: %q", body)
+	}
+}
+
+type chanReporter chan error
+
+func (c chanReporter) Report(_ context.Context, err error, _ *http.Request) {
+	c <- err
+}
+
+func TestPanicAfterPartialWriteIsReported(t *testing.T) {
+	reports := make(chanReporter, 1)
+	g := NewGoWay()
+	g.ErrorReporter = reports
+	g.GET("/partial", func(c *GoWayContext) {
+		c.Write([]byte("partial"))
+		panic("boom")
+	})
+
+	func() {
+		defer func() {
+			if rec := recover(); rec != http.ErrAbortHandler {
+				t.Fatalf("recovered %v, want http.ErrAbortHandler", rec)
+			}
+		}()
+		g.Test(http.MethodGet, "/partial", nil)
+	}()
+
+	select {
+	case err := <-reports:
+		if !strings.Contains(err.Error(), "boom") {
+			t.Errorf("reported %v, want the panic value", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("panic after a partial write was not reported")
+	}
+}
+
+func headerMiddleware(key, value string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set(key, value)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func TestUseAfterRoutesAppliesToThem(t *testing.T) {
+	g := NewGoWay()
+	g.GET("/ping", func(c *GoWayContext) { c.Data(http.StatusOK, "text/plain", []byte("pong")) })
+	g.Use(headerMiddleware("X-First", "1"))
+
+	rec := g.Test(http.MethodGet, "/ping", nil)
+	if rec.Header().Get("X-First") != "1" {
+		t.Fatalf("middleware registered after the route did not run")
+	}
+
+	// La cadena ya se construyó en la primera petición: Use debe rehacerla
+	g.Use(headerMiddleware("X-Second", "2"))
+	rec = g.Test(http.MethodGet, "/ping", nil)
+	if rec.Header().Get("X-First") != "1" || rec.Header().Get("X-Second") != "2" {
+		t.Errorf("headers after second Use = %v, want X-First and X-Second", rec.Header())
+	}
+}
@@ -0,0 +1,73 @@
+package goway
+
+import "net/http"
+
+// Grupo de rutas con prefijo, middlewares y manejador de errores comunes
+type Group struct {
+	g            *GoWay
+	parent       *Group
+	host         *hostRouter // Host al que responden las rutas (nil = cualquiera)
+	prefix       string
+	middlewares  []func(http.Handler) http.Handler
+	errorHandler ErrorHandlerFunc
+}
+
+// Crear un grupo de rutas bajo prefix. Los middlewares se aplican a todas
+// sus rutas, después de los globales.
+func (g *GoWay) Group(prefix string, middlewares ...func(http.Handler) http.Handler) *Group {
+	return &Group{g: g, prefix: prefix, middlewares: middlewares}
+}
+
+// Crear un subgrupo que hereda prefijo, middlewares y manejador de errores
+func (gr *Group) Group(prefix string, middlewares ...func(http.Handler) http.Handler) *Group {
+	return &Group{
+		g:           gr.g,
+		parent:      gr,
+		host:        gr.host,
+		prefix:      gr.prefix + prefix,
+		middlewares: append(append([]func(http.Handler) http.Handler{}, gr.middlewares...), middlewares...),
+	}
+}
+
+// Usar fn para enviar los errores de las rutas de este grupo (y sus
+// subgrupos) en lugar del manejador global
+func (gr *Group) SetErrorHandler(fn ErrorHandlerFunc) {
+	gr.errorHandler = fn
+}
+
+// Manejador de errores más específico: el del grupo más interno que lo tenga
+func (gr *Group) findErrorHandler() ErrorHandlerFunc {
+	for ; gr != nil; gr = gr.parent {
+		if gr.errorHandler != nil {
+			return gr.errorHandler
+		}
+	}
+	return nil
+}
+
+func (gr *Group) Handle(method, pattern string, handler GoWayHandlerFunc, middlewares ...func(http.Handler) http.Handler) {
+	rt := newRoute(method, gr.prefix+pattern, handler, gr.routeMiddlewares(middlewares))
+	rt.group = gr
+	gr.g.addRoute(rt)
+}
+
+func (gr *Group) GET(pattern string, handler GoWayHandlerFunc, middlewares ...func(http.Handler) http.Handler) {
+	gr.Handle("GET", pattern, handler, middlewares...)
+}
+
+func (gr *Group) POST(pattern string, handler GoWayHandlerFunc, middlewares ...func(http.Handler) http.Handler) {
+	gr.Handle("POST", pattern, handler, middlewares...)
+}
+
+func (gr *Group) Any(pattern string, handler GoWayHandlerFunc, middlewares ...func(http.Handler) http.Handler) {
+	for _, method := range anyMethods {
+		rt := newRoute(method, gr.prefix+pattern, handler, gr.routeMiddlewares(middlewares))
+		rt.group = gr
+		rt.any = true
+		gr.g.addRoute(rt)
+	}
+}
+
+func (gr *Group) routeMiddlewares(middlewares []func(http.Handler) http.Handler) []func(http.Handler) http.Handler {
+	return append(append([]func(http.Handler) http.Handler{}, gr.middlewares...), middlewares...)
+}
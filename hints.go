@@ -0,0 +1,28 @@
+package goway
+
+import (
+	"net/http"
+	"strings"
+)
+
+// Enviar una respuesta 103 Early Hints con cabeceras Link para que el
+// navegador precargue recursos mientras se prepara la respuesta. Cada link
+// puede ser un valor Link completo ("</app.css>; rel=preload; as=style") o
+// solo la URL, que se envía como rel=preload. No hace nada con clientes
+// HTTP/1.0, que no admiten respuestas informativas.
+func (c *GoWayContext) EarlyHints(links []string) {
+	if len(links) == 0 || !c.r.ProtoAtLeast(1, 1) {
+		return
+	}
+	if rw := stateFrom(c.r).rw; rw != nil && rw.Written() {
+		return
+	}
+	h := c.w.Header()
+	for _, link := range links {
+		if !strings.HasPrefix(link, "<") {
+			link = "<" + link + ">; rel=preload"
+		}
+		h.Add("Link", link)
+	}
+	c.w.WriteHeader(http.StatusEarlyHints)
+}
@@ -0,0 +1,122 @@
+package goway
+
+import (
+	"net"
+	"net/http"
+	"strings"
+)
+
+// Árbol de rutas para un patrón de host, por ejemplo "api.example.com" o
+// "{tenant}.example.com"
+type hostRouter struct {
+	pattern string
+	labels  []string
+	tree    *node
+}
+
+// Crear un grupo cuyas rutas solo responden a peticiones con ese Host. Un
+// segmento "{nombre}" captura esa parte del host, accesible con PathParam,
+// y "*" acepta cualquier valor. Las peticiones a hosts sin grupo usan las
+// rutas registradas directamente en GoWay.
+//
+//	g.Host("{tenant}.example.com").GET("/", home)
+func (g *GoWay) Host(pattern string) *Group {
+	pattern = strings.ToLower(pattern)
+	for _, h := range g.hosts {
+		if h.pattern == pattern {
+			return &Group{g: g, host: h}
+		}
+	}
+	h := &hostRouter{pattern: pattern, labels: strings.Split(pattern, "."), tree: newNode()}
+	g.hosts = append(g.hosts, h)
+	return &Group{g: g, host: h}
+}
+
+// Elegir el árbol para el host de la petición. Los hosts exactos tienen
+// prioridad sobre los que llevan parámetros o comodines.
+func (g *GoWay) treeFor(host string) (*node, []pathParam) {
+	if len(g.hosts) == 0 {
+		return g.tree, nil
+	}
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	labels := strings.Split(strings.ToLower(host), ".")
+	var best *hostRouter
+	var bestParams []pathParam
+	for _, h := range g.hosts {
+		params, ok := h.match(labels)
+		if !ok {
+			continue
+		}
+		if len(params) == 0 && !strings.Contains(h.pattern, "*") {
+			return h.tree, nil
+		}
+		if best == nil {
+			best, bestParams = h, params
+		}
+	}
+	if best != nil {
+		return best.tree, bestParams
+	}
+	return g.tree, nil
+}
+
+func (h *hostRouter) match(labels []string) ([]pathParam, bool) {
+	if len(labels) != len(h.labels) {
+		return nil, false
+	}
+	var params []pathParam
+	for i, label := range h.labels {
+		switch {
+		case label == "*":
+		case strings.HasPrefix(label, "{") && strings.HasSuffix(label, "}"):
+			params = append(params, pathParam{label[1 : len(label)-1], labels[i]})
+		case label != labels[i]:
+			return nil, false
+		}
+	}
+	return params, true
+}
+
+// Middleware que rechaza con 400 las peticiones cuyo Host no está en hosts,
+// para evitar ataques con la cabecera Host (enlaces de reseteo de contraseña
+// con otro dominio, envenenamiento de caches). "*.example.com" acepta
+// cualquier subdominio de example.com, pero no example.com. El puerto se
+// ignora. Debe registrarse con Use para que actúe antes que cualquier ruta.
+func AllowedHostsMiddleware(hosts ...string) func(http.Handler) http.Handler {
+	exact := make(map[string]bool)
+	var suffixes []string
+	for _, h := range hosts {
+		h = strings.TrimSuffix(strings.ToLower(h), ".")
+		if suffix, ok := strings.CutPrefix(h, "*"); ok {
+			suffixes = append(suffixes, suffix)
+		} else {
+			exact[h] = true
+		}
+	}
+	allowed := func(host string) bool {
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+		host = strings.TrimSuffix(strings.ToLower(host), ".")
+		if exact[host] {
+			return true
+		}
+		for _, suffix := range suffixes {
+			if len(host) > len(suffix) && strings.HasSuffix(host, suffix) {
+				return true
+			}
+		}
+		return false
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !allowed(r.Host) {
+				writeError(w, r, NewCustomError("Invalid Host header", http.StatusBadRequest))
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
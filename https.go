@@ -0,0 +1,81 @@
+package goway
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Opciones de la cabecera Strict-Transport-Security
+type HSTSOption func(*hstsConfig)
+
+type hstsConfig struct {
+	maxAge            time.Duration
+	includeSubdomains bool
+	preload           bool
+}
+
+// Duración de la política HSTS (por defecto un año)
+func HSTSMaxAge(d time.Duration) HSTSOption {
+	return func(c *hstsConfig) { c.maxAge = d }
+}
+
+// Aplicar la política también a los subdominios
+func HSTSIncludeSubdomains() HSTSOption {
+	return func(c *hstsConfig) { c.includeSubdomains = true }
+}
+
+// Marcar el dominio para la lista de precarga de los navegadores
+func HSTSPreload() HSTSOption {
+	return func(c *hstsConfig) { c.preload = true }
+}
+
+func (c *hstsConfig) header() string {
+	value := "max-age=" + strconv.Itoa(int(c.maxAge.Seconds()))
+	if c.includeSubdomains {
+		value += "; includeSubDomains"
+	}
+	if c.preload {
+		value += "; preload"
+	}
+	return value
+}
+
+// Middleware que exige HTTPS. Las peticiones en claro se redirigen con 301 a
+// la misma URL con https (si redirect) o se rechazan con 400. En HTTPS añade
+// Strict-Transport-Security. X-Forwarded-Proto solo se tiene en cuenta si la
+// petición viene de un proxy de confianza.
+func RequireHTTPSMiddleware(redirect bool, opts ...HSTSOption) func(http.Handler) http.Handler {
+	cfg := &hstsConfig{maxAge: 365 * 24 * time.Hour}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	hsts := cfg.header()
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !isHTTPS(r) {
+				if !redirect {
+					http.Error(w, "HTTPS required", http.StatusBadRequest)
+					return
+				}
+				http.Redirect(w, r, "https://"+r.Host+r.URL.RequestURI(), http.StatusMovedPermanently)
+				return
+			}
+			w.Header().Set("Strict-Transport-Security", hsts)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// Indica si el cliente llegó por HTTPS, directamente o a través de un proxy de confianza
+func isHTTPS(r *http.Request) bool {
+	if r.TLS != nil {
+		return true
+	}
+	if !fromTrustedProxy(r) {
+		return false
+	}
+	proto, _, _ := strings.Cut(r.Header.Get("X-Forwarded-Proto"), ",")
+	return strings.EqualFold(strings.TrimSpace(proto), "https")
+}
@@ -0,0 +1,134 @@
+package goway
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// Respuesta guardada para una clave de idempotencia
+type IdempotentResponse struct {
+	Status int
+	Header http.Header
+	Body   []byte
+}
+
+// Almacén de respuestas de IdempotencyMiddleware. MemoryIdempotencyStore
+// sirve para una sola instancia; con varias conviene uno compartido (Redis...).
+type IdempotencyStore interface {
+	// Respuesta guardada para key, o nil si no hay o caducó
+	Get(ctx context.Context, key string) (*IdempotentResponse, error)
+	// Guardar la respuesta de key durante ttl
+	Set(ctx context.Context, key string, resp *IdempotentResponse, ttl time.Duration) error
+}
+
+// Middleware que hace idempotentes las peticiones con cabecera
+// Idempotency-Key: la primera respuesta para una clave se guarda durante ttl
+// y las repeticiones reciben la misma respuesta (status, cabeceras y cuerpo)
+// sin volver a ejecutar el manejador, con Idempotent-Replayed: true. Las
+// peticiones con la misma clave que llegan mientras la primera se atiende
+// esperan a que termine. Los 5xx no se guardan para poder reintentar.
+func IdempotencyMiddleware(store IdempotencyStore, ttl time.Duration) func(http.Handler) http.Handler {
+	var group singleflight.Group
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			idemKey := r.Header.Get("Idempotency-Key")
+			if idemKey == "" || r.Method == http.MethodGet || r.Method == http.MethodHead || r.Method == http.MethodOptions {
+				next.ServeHTTP(w, r)
+				return
+			}
+			// La misma clave en otro endpoint es otra operación
+			key := r.Method + " " + r.URL.Path + " " + idemKey
+			if resp, err := store.Get(r.Context(), key); err != nil {
+				loggerFrom(r).Errorf("goway: idempotency store: %v", err)
+			} else if resp != nil {
+				replayResponse(w, resp)
+				return
+			}
+
+			// Otra petición con la misma clave pudo terminar entre Get y Do:
+			// el líder vuelve a consultar el almacén antes de ejecutar el manejador
+			stored := false
+			v, leader := doFlight(&group, key, func() any {
+				if resp, err := store.Get(r.Context(), key); err == nil && resp != nil {
+					stored = true
+					return resp
+				}
+				rec := &recordWriter{ResponseWriter: w}
+				next.ServeHTTP(rec, r)
+				resp := &IdempotentResponse{
+					Status: rec.status,
+					Header: w.Header().Clone(),
+					Body:   rec.body.Bytes(),
+				}
+				if resp.Status == 0 {
+					resp.Status = http.StatusOK
+				}
+				if resp.Status < 500 {
+					if err := store.Set(context.WithoutCancel(r.Context()), key, resp, ttl); err != nil {
+						loggerFrom(r).Errorf("goway: idempotency store: %v", err)
+					}
+				}
+				return resp
+			})
+			if !leader || stored {
+				replayResponse(w, v.(*IdempotentResponse))
+			}
+		})
+	}
+}
+
+func replayResponse(w http.ResponseWriter, resp *IdempotentResponse) {
+	for k, v := range resp.Header {
+		w.Header()[k] = v
+	}
+	w.Header().Set("Idempotent-Replayed", "true")
+	w.WriteHeader(resp.Status)
+	w.Write(resp.Body)
+}
+
+// Almacén en memoria para IdempotencyMiddleware, seguro para uso concurrente
+type MemoryIdempotencyStore struct {
+	mu      sync.Mutex
+	entries map[string]idempotencyEntry
+}
+
+type idempotencyEntry struct {
+	resp    *IdempotentResponse
+	expires time.Time
+}
+
+func NewMemoryIdempotencyStore() *MemoryIdempotencyStore {
+	return &MemoryIdempotencyStore{entries: make(map[string]idempotencyEntry)}
+}
+
+func (s *MemoryIdempotencyStore) Get(_ context.Context, key string) (*IdempotentResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entry, ok := s.entries[key]
+	if !ok {
+		return nil, nil
+	}
+	if time.Now().After(entry.expires) {
+		delete(s.entries, key)
+		return nil, nil
+	}
+	return entry.resp, nil
+}
+
+func (s *MemoryIdempotencyStore) Set(_ context.Context, key string, resp *IdempotentResponse, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	// Aprovechar la escritura para descartar las caducadas
+	for k, entry := range s.entries {
+		if now.After(entry.expires) {
+			delete(s.entries, k)
+		}
+	}
+	s.entries[key] = idempotencyEntry{resp: resp, expires: now.Add(ttl)}
+	return nil
+}
@@ -0,0 +1,58 @@
+package goway
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// Almacén cuya primera consulta no encuentra la respuesta aunque otra
+// petición la acaba de guardar, como si hubiera terminado entre Get y Do
+type lateStore struct {
+	*MemoryIdempotencyStore
+	gets int
+}
+
+func (s *lateStore) Get(ctx context.Context, key string) (*IdempotentResponse, error) {
+	s.gets++
+	if s.gets == 1 {
+		resp := &IdempotentResponse{Status: http.StatusCreated, Header: http.Header{}, Body: []byte("first")}
+		s.MemoryIdempotencyStore.Set(ctx, key, resp, time.Minute)
+		return nil, nil
+	}
+	return s.MemoryIdempotencyStore.Get(ctx, key)
+}
+
+func TestIdempotencyRechecksStoreBeforeRunning(t *testing.T) {
+	runs := 0
+	g := NewGoWay()
+	g.POST("/orders", func(c *GoWayContext) {
+		runs++
+		c.w.WriteHeader(http.StatusCreated)
+	}, IdempotencyMiddleware(&lateStore{MemoryIdempotencyStore: NewMemoryIdempotencyStore()}, time.Minute))
+
+	r := httptest.NewRequest(http.MethodPost, "/orders", nil)
+	r.Header.Set("Idempotency-Key", "abc")
+	rec := g.TestRequest(r)
+	if runs != 0 {
+		t.Fatalf("handler ran %d times, want the stored response replayed", runs)
+	}
+	if rec.Code != http.StatusCreated || rec.Body.String() != "first" || rec.Header().Get("Idempotent-Replayed") != "true" {
+		t.Errorf("got %d %q (replayed %q), want the stored 201 %q", rec.Code, rec.Body.String(), rec.Header().Get("Idempotent-Replayed"), "first")
+	}
+}
+
+func TestIdempotencyKeepsHandlerPanic(t *testing.T) {
+	g := NewGoWay()
+	g.POST("/orders", func(c *GoWayContext) {
+		panic(NewCustomError("order conflict", http.StatusConflict))
+	}, IdempotencyMiddleware(NewMemoryIdempotencyStore(), time.Minute))
+
+	r := httptest.NewRequest(http.MethodPost, "/orders", nil)
+	r.Header.Set("Idempotency-Key", "abc")
+	if rec := g.TestRequest(r); rec.Code != http.StatusConflict {
+		t.Errorf("status = %d, want %d from the CustomError panic", rec.Code, http.StatusConflict)
+	}
+}
@@ -0,0 +1,52 @@
+package goway
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// Profundidad máxima de JSON aceptada por defecto en Body
+const DefaultMaxJSONDepth = 64
+
+// Límite de anidamiento configurado en el servidor de la petición
+func maxJSONDepth(r *http.Request) int {
+	g := goWayFrom(r)
+	if g == nil || g.MaxJSONDepth == 0 {
+		return DefaultMaxJSONDepth
+	}
+	return g.MaxJSONDepth
+}
+
+// Recorrer el JSON contando objetos y arrays abiertos, sin decodificarlo, y
+// devolver un CustomError 400 si se supera max. Los corchetes dentro de
+// strings no cuentan.
+func checkJSONDepth(data []byte, max int) error {
+	if max < 0 {
+		return nil
+	}
+	depth := 0
+	inString, escaped := false, false
+	for _, b := range data {
+		switch {
+		case inString:
+			switch {
+			case escaped:
+				escaped = false
+			case b == '\\':
+				escaped = true
+			case b == '"':
+				inString = false
+			}
+		case b == '"':
+			inString = true
+		case b == '{' || b == '[':
+			depth++
+			if depth > max {
+				return NewCustomError(fmt.Sprintf("JSON nesting exceeds maximum depth of %d", max), http.StatusBadRequest)
+			}
+		case b == '}' || b == ']':
+			depth--
+		}
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+package goway
+
+import (
+	"context"
+	"errors"
+	"net/http"
+)
+
+// Escritor de un objeto JSON por partes, usado en JSONObjectStream
+type StreamEncoder struct {
+	w      http.ResponseWriter
+	r      *http.Request
+	fields int
+	err    error
+}
+
+// Escribir una clave con su valor ya completo
+func (e *StreamEncoder) Field(key string, value any) error {
+	data, err := marshalJSON(e.r, value)
+	if err != nil {
+		return err
+	}
+	if err := e.key(key); err != nil {
+		return err
+	}
+	return e.write(data)
+}
+
+// Escribir una clave cuyo valor es un array con los elementos que llegan por
+// items, enviándolos según llegan. Termina cuando se cierra items o se
+// cancela la petición.
+func (e *StreamEncoder) StreamArray(key string, items <-chan any) error {
+	if err := e.key(key); err != nil {
+		return err
+	}
+	if err := e.write([]byte("[")); err != nil {
+		return err
+	}
+	for n := 0; ; n++ {
+		var item any
+		var ok bool
+		select {
+		case item, ok = <-items:
+		case <-e.r.Context().Done():
+			return e.r.Context().Err()
+		}
+		if !ok {
+			break
+		}
+		data, err := marshalJSON(e.r, item)
+		if err != nil {
+			// Cerrar el array para que el documento siga siendo válido
+			e.write([]byte("]"))
+			return err
+		}
+		if n > 0 {
+			data = append([]byte(","), data...)
+		}
+		if err := e.write(data); err != nil {
+			return err
+		}
+		http.NewResponseController(e.w).Flush()
+	}
+	return e.write([]byte("]"))
+}
+
+func (e *StreamEncoder) key(key string) error {
+	data, err := marshalJSON(e.r, key)
+	if err != nil {
+		return err
+	}
+	if e.fields > 0 {
+		data = append([]byte(","), data...)
+	}
+	e.fields++
+	return e.write(append(data, ':'))
+}
+
+func (e *StreamEncoder) write(data []byte) error {
+	if e.err != nil {
+		return e.err
+	}
+	_, e.err = e.w.Write(data)
+	return e.err
+}
+
+// Enviar un objeto JSON que se construye por partes: fn escribe sus claves
+// con el StreamEncoder, pudiendo mezclar valores completos (Field) con arrays
+// que se envían según se generan (StreamArray).
+//
+// El status y las cabeceras se envían antes de llamar a fn, así que ya no se
+// pueden cambiar. Si fn devuelve un error el objeto se cierra con una clave
+// "error" con el mensaje (genérico para errores que no son *CustomError)
+// para que el cliente reciba un JSON válido, y se devuelve el error.
+//
+//	c.JSONObjectStream(http.StatusOK, func(enc *goway.StreamEncoder) error {
+//		if err := enc.Field("facets", facets); err != nil {
+//			return err
+//		}
+//		return enc.StreamArray("results", results)
+//	})
+func (c *GoWayContext) JSONObjectStream(status int, fn func(enc *StreamEncoder) error) error {
+	c.w.Header().Set("Content-Type", "application/json")
+	c.w.WriteHeader(status)
+	enc := &StreamEncoder{w: c.w, r: c.r}
+	if err := enc.write([]byte("{")); err != nil {
+		return err
+	}
+	err := fn(enc)
+	if err != nil && enc.err == nil && !errors.Is(err, context.Canceled) {
+		msg := "Internal Server Error"
+		var customErr *CustomError
+		if errors.As(err, &customErr) {
+			msg = customErr.Message
+		} else {
+			loggerFrom(c.r).Errorf("goway: JSON stream: %v", err)
+		}
+		enc.Field("error", msg)
+	}
+	if werr := enc.write([]byte("}\n")); err == nil {
+		err = werr
+	}
+	return err
+}
@@ -0,0 +1,86 @@
+package goway
+
+import (
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// Límite de peticiones atendidas a la vez, con una cola acotada para
+// absorber picos. Las peticiones que no caben en la cola, o que esperan más
+// de maxWait, reciben un 503.
+type ConcurrencyLimiter struct {
+	sem     chan struct{}
+	queue   int64
+	maxWait time.Duration
+	queued  atomic.Int64
+}
+
+// Crear un limitador de max peticiones simultáneas que deja esperar hasta
+// queue peticiones más durante como mucho maxWait. Con queue 0 se responde
+// 503 en cuanto no queda hueco.
+//
+//	limiter := goway.NewConcurrencyLimiter(100, 200, 2*time.Second)
+//	g.Use(limiter.Middleware())
+func NewConcurrencyLimiter(max, queue int, maxWait time.Duration) *ConcurrencyLimiter {
+	if max <= 0 {
+		max = 1
+	}
+	return &ConcurrencyLimiter{
+		sem:     make(chan struct{}, max),
+		queue:   int64(queue),
+		maxWait: maxWait,
+	}
+}
+
+// Peticiones en curso
+func (l *ConcurrencyLimiter) Active() int {
+	return len(l.sem)
+}
+
+// Peticiones esperando en la cola, para métricas
+func (l *ConcurrencyLimiter) QueueDepth() int {
+	return int(l.queued.Load())
+}
+
+func (l *ConcurrencyLimiter) Middleware() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !l.acquire(r) {
+				if r.Context().Err() != nil {
+					// El cliente se fue mientras esperaba
+					return
+				}
+				w.Header().Set("Retry-After", "1")
+				writeError(w, r, NewCustomError("Service Unavailable", http.StatusServiceUnavailable))
+				return
+			}
+			defer func() { <-l.sem }()
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// Ocupar un hueco, esperando en la cola si está todo lleno
+func (l *ConcurrencyLimiter) acquire(r *http.Request) bool {
+	select {
+	case l.sem <- struct{}{}:
+		return true
+	default:
+	}
+	if l.queued.Add(1) > l.queue {
+		l.queued.Add(-1)
+		return false
+	}
+	defer l.queued.Add(-1)
+	timer := time.NewTimer(l.maxWait)
+	defer timer.Stop()
+	select {
+	case l.sem <- struct{}{}:
+		return true
+	case <-timer.C:
+		return false
+	case <-r.Context().Done():
+		return false
+	}
+}
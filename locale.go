@@ -0,0 +1,87 @@
+package goway
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Middleware que elige el idioma de la petición entre los soportados.
+// Prioridad: parámetro ?lang=, cabecera Accept-Language y por último def.
+func LocaleMiddleware(supported []string, def string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			locale, ok := matchLocale(r.URL.Query().Get("lang"), supported)
+			if !ok {
+				locale, ok = bestLocale(r.Header.Get("Accept-Language"), supported)
+			}
+			if !ok {
+				locale = def
+			}
+			ctx := context.WithValue(r.Context(), localeKey, locale)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// Idioma elegido por LocaleMiddleware (vacío si no se usa el middleware)
+func (c *GoWayContext) Locale() string {
+	locale, _ := c.r.Context().Value(localeKey).(string)
+	return locale
+}
+
+type langQuality struct {
+	tag string
+	q   float64
+}
+
+// Recorrer Accept-Language por orden de calidad y devolver el primer soportado
+func bestLocale(header string, supported []string) (string, bool) {
+	var langs []langQuality
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if tag == "" || tag == "*" {
+			continue
+		}
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		if q > 0 {
+			langs = append(langs, langQuality{tag, q})
+		}
+	}
+	sort.SliceStable(langs, func(i, j int) bool { return langs[i].q > langs[j].q })
+	for _, l := range langs {
+		if locale, ok := matchLocale(l.tag, supported); ok {
+			return locale, true
+		}
+	}
+	return "", false
+}
+
+// Buscar la etiqueta exacta y si no, una con el mismo idioma base ("fr-CH" ~ "fr")
+func matchLocale(tag string, supported []string) (string, bool) {
+	if tag == "" {
+		return "", false
+	}
+	for _, s := range supported {
+		if strings.EqualFold(s, tag) {
+			return s, true
+		}
+	}
+	base, _, _ := strings.Cut(tag, "-")
+	for _, s := range supported {
+		sBase, _, _ := strings.Cut(s, "-")
+		if strings.EqualFold(sBase, base) {
+			return s, true
+		}
+	}
+	return "", false
+}
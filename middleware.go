@@ -0,0 +1,199 @@
+package goway
+
+import (
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+)
+
+// Métodos que se pueden simular desde un POST
+var overridableMethods = map[string]bool{
+	http.MethodPut:    true,
+	http.MethodPatch:  true,
+	http.MethodDelete: true,
+}
+
+// Middleware que permite a clientes limitados a GET/POST enviar PUT, PATCH o
+// DELETE. Solo actúa sobre POST y toma el método de la cabecera
+// X-HTTP-Method-Override o del campo _method de un formulario. Debe
+// registrarse con Use para que el router vea el método reescrito.
+func MethodOverrideMiddleware() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodPost {
+				method := r.Header.Get("X-HTTP-Method-Override")
+				if method == "" && strings.HasPrefix(r.Header.Get("Content-Type"), "application/x-www-form-urlencoded") {
+					method = r.PostFormValue("_method")
+				}
+				if method = strings.ToUpper(method); overridableMethods[method] {
+					r.Method = method
+				}
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// Aplicar mw solo a las peticiones con alguno de los métodos indicados; el
+// resto pasa directamente al siguiente manejador.
+//
+//	g.Use(goway.OnMethods([]string{"POST", "PUT", "PATCH"}, bodyLimit))
+func OnMethods(methods []string, mw func(http.Handler) http.Handler) func(http.Handler) http.Handler {
+	set := make(map[string]bool, len(methods))
+	for _, m := range methods {
+		set[strings.ToUpper(m)] = true
+	}
+	return func(next http.Handler) http.Handler {
+		wrapped := mw(next)
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if set[r.Method] {
+				wrapped.ServeHTTP(w, r)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// Middleware que normaliza el path: une barras repetidas y resuelve "." y
+// "..". Los GET y HEAD se redirigen con 301 al path limpio; el resto de
+// métodos se reescriben sin redirección. Los paths ya limpios no se tocan.
+func PathCleanMiddleware() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			p := r.URL.Path
+			clean := cleanPath(p)
+			if clean == p {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if r.Method == http.MethodGet || r.Method == http.MethodHead {
+				u := *r.URL
+				u.Path = clean
+				u.RawPath = ""
+				http.Redirect(w, r, u.RequestURI(), http.StatusMovedPermanently)
+				return
+			}
+			r.URL.Path = clean
+			r.URL.RawPath = ""
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// path.Clean conservando la barra final
+func cleanPath(p string) string {
+	if p == "" {
+		return "/"
+	}
+	clean := path.Clean("/" + p)
+	if strings.HasSuffix(p, "/") && clean != "/" {
+		clean += "/"
+	}
+	return clean
+}
+
+// Middleware que quita prefix del path antes de que el router lo resuelva,
+// para servicios publicados bajo un prefijo del gateway ("/service-a").
+// Ajusta también RawPath. Las peticiones que no empiezan por el prefijo
+// reciben un 404. Debe registrarse con Use.
+func StripPrefixMiddleware(prefix string) func(http.Handler) http.Handler {
+	prefix = strings.TrimSuffix(prefix, "/")
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			p, ok := stripPrefix(r.URL.Path, prefix)
+			if !ok {
+				writeError(w, r, NewCustomError("Not Found", http.StatusNotFound))
+				return
+			}
+			r2 := new(http.Request)
+			*r2 = *r
+			r2.URL = new(url.URL)
+			*r2.URL = *r.URL
+			r2.URL.Path = p
+			if r.URL.RawPath != "" {
+				// Si el prefijo escapado no coincide se deja que net/url lo recalcule
+				r2.URL.RawPath, _ = stripPrefix(r.URL.RawPath, prefix)
+			}
+			next.ServeHTTP(w, r2)
+		})
+	}
+}
+
+// Quitar el prefijo solo si termina en un límite de segmento
+func stripPrefix(p, prefix string) (string, bool) {
+	switch {
+	case prefix == "":
+		return p, true
+	case p == prefix:
+		return "/", true
+	case strings.HasPrefix(p, prefix+"/"):
+		return p[len(prefix):], true
+	}
+	return "", false
+}
+
+// Límites por defecto de QueryLimitsMiddleware, holgados para no molestar a
+// clientes normales
+const (
+	DefaultMaxQueryLength = 8 << 10
+	DefaultMaxQueryParams = 1000
+	DefaultMaxHeaders     = 100
+	DefaultMaxSegments    = 64
+)
+
+// Middleware que rechaza antes de parsear nada las peticiones con una query
+// de más de maxLength bytes o más de maxParams parámetros (414) o con más de
+// maxHeaders cabeceras (431). Un límite a 0 usa el valor por defecto.
+// Conviene registrarlo con Use al principio de la cadena.
+func QueryLimitsMiddleware(maxLength, maxParams, maxHeaders int) func(http.Handler) http.Handler {
+	if maxLength <= 0 {
+		maxLength = DefaultMaxQueryLength
+	}
+	if maxParams <= 0 {
+		maxParams = DefaultMaxQueryParams
+	}
+	if maxHeaders <= 0 {
+		maxHeaders = DefaultMaxHeaders
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			query := r.URL.RawQuery
+			// Contar separadores en vez de parsear la query, que es justo lo que se quiere evitar
+			if len(query) > maxLength || query != "" && strings.Count(query, "&")+1 > maxParams {
+				writeError(w, r, NewCustomError("Query string too long", http.StatusRequestURITooLong))
+				return
+			}
+			headers := 0
+			for _, v := range r.Header {
+				headers += len(v)
+			}
+			if headers > maxHeaders {
+				writeError(w, r, NewCustomError("Too many request headers", http.StatusRequestHeaderFieldsTooLarge))
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// Middleware que rechaza con 414 los paths de más de max segmentos, para
+// limitar el trabajo del router con paths patológicos en rutas comodín. Con 0
+// usa DefaultMaxSegments. Debe registrarse con Use para actuar antes del
+// router.
+func MaxPathSegmentsMiddleware(max int) func(http.Handler) http.Handler {
+	if max <= 0 {
+		max = DefaultMaxSegments
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// Contar barras es suficiente: cada segmento empieza por una
+			if strings.Count(r.URL.Path, "/") > max {
+				writeError(w, r, NewCustomError("Too many path segments", http.StatusRequestURITooLong))
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,34 @@
+package goway
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestOnMethodsSkipsOtherMethods(t *testing.T) {
+	calls := 0
+	mw := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			next.ServeHTTP(w, r)
+		})
+	}
+	g := NewGoWay()
+	g.Use(OnMethods([]string{"POST"}, mw))
+	ok := func(c *GoWayContext) { c.w.WriteHeader(http.StatusNoContent) }
+	g.GET("/items", ok)
+	g.POST("/items", ok)
+
+	if rec := g.Test(http.MethodPost, "/items", nil); rec.Code != http.StatusNoContent {
+		t.Fatalf("POST status = %d, want %d", rec.Code, http.StatusNoContent)
+	}
+	if calls != 1 {
+		t.Fatalf("middleware ran %d times for POST, want 1", calls)
+	}
+	if rec := g.Test(http.MethodGet, "/items", nil); rec.Code != http.StatusNoContent {
+		t.Fatalf("GET status = %d, want %d", rec.Code, http.StatusNoContent)
+	}
+	if calls != 1 {
+		t.Errorf("middleware ran for GET (%d calls), want it skipped", calls)
+	}
+}
@@ -0,0 +1,40 @@
+package goway
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// Leer un cuerpo NDJSON (un objeto JSON por línea) llamando a fn con cada
+// objeto según llega, sin cargar todo el cuerpo en memoria. Se detiene y
+// devuelve el error en cuanto fn falla. Un JSON mal formado devuelve un
+// CustomError 400 indicando el número de objeto.
+func (c *GoWayContext) BindNDJSON(fn func(v json.RawMessage) error) error {
+	if err := c.limitBody(); err != nil {
+		return err
+	}
+	defer c.r.Body.Close()
+	dec := json.NewDecoder(c.r.Body)
+	maxDepth := maxJSONDepth(c.r)
+	for n := 1; ; n++ {
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			if err = bodyError(err); errors.As(err, new(*CustomError)) {
+				return err
+			}
+			return NewCustomError(fmt.Sprintf("invalid JSON in object %d: %v", n, err), http.StatusBadRequest)
+		}
+		if err := checkJSONDepth(raw, maxDepth); err != nil {
+			return err
+		}
+		if err := fn(raw); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package goway
+
+import (
+	"fmt"
+	"math"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Leer ?page= y ?limit= de la query. page empieza en 1 y limit se ajusta a
+// [1, maxLimit]; los valores ausentes o no válidos toman el de por defecto
+// en vez de dar error. offset es el número de elementos a saltar; un page muy
+// grande se recorta para que no se desborde.
+//
+//	page, limit, offset := c.Pagination(20, 100)
+func (c *GoWayContext) Pagination(defaultLimit, maxLimit int) (page, limit, offset int) {
+	query := c.r.URL.Query()
+	page, err := strconv.Atoi(query.Get("page"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+	limit, err = strconv.Atoi(query.Get("limit"))
+	if err != nil || limit < 1 {
+		limit = defaultLimit
+	}
+	if maxLimit > 0 && limit > maxLimit {
+		limit = maxLimit
+	}
+	if limit < 1 {
+		limit = 1
+	}
+	page = min(page, math.MaxInt/limit)
+	return page, limit, (page - 1) * limit
+}
+
+// Añadir la cabecera Link con las relaciones first, prev, next y last (al
+// estilo de la API de GitHub) y X-Total-Count con total. Los enlaces son base
+// con los parámetros page y limit, los mismos que lee Pagination; se omiten
+// las relaciones que no aplican, como next en la última página.
+//
+//	c.SetPaginationLinks("https://api.example.com/users", page, limit, total)
+func (c *GoWayContext) SetPaginationLinks(base string, page, perPage, total int) {
+	c.w.Header().Set("X-Total-Count", strconv.Itoa(total))
+	if perPage < 1 {
+		return
+	}
+	u, err := url.Parse(base)
+	if err != nil {
+		return
+	}
+	last := (total + perPage - 1) / perPage
+	if last < 1 {
+		last = 1
+	}
+	link := func(p int, rel string) string {
+		q := u.Query()
+		q.Set("page", strconv.Itoa(p))
+		q.Set("limit", strconv.Itoa(perPage))
+		target := *u
+		target.RawQuery = q.Encode()
+		return fmt.Sprintf("<%s>; rel=%q", target.String(), rel)
+	}
+	var links []string
+	if page > 1 {
+		links = append(links, link(1, "first"), link(min(page-1, last), "prev"))
+	}
+	if page < last {
+		links = append(links, link(page+1, "next"), link(last, "last"))
+	}
+	if len(links) > 0 {
+		c.w.Header().Set("Link", strings.Join(links, ", "))
+	}
+}
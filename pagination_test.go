@@ -0,0 +1,22 @@
+package goway
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"testing"
+)
+
+func TestPaginationHugePageDoesNotOverflow(t *testing.T) {
+	var page, limit, offset int
+	g := NewGoWay()
+	g.GET("/items", func(c *GoWayContext) { page, limit, offset = c.Pagination(20, 100) })
+
+	g.Test(http.MethodGet, "/items?limit=50&page="+strconv.Itoa(math.MaxInt), nil)
+	if offset < 0 {
+		t.Fatalf("offset = %d for page %d, want no overflow", offset, page)
+	}
+	if limit != 50 || offset != (page-1)*limit {
+		t.Errorf("Pagination() = %d, %d, %d, want offset (page-1)*limit with limit 50", page, limit, offset)
+	}
+}
@@ -0,0 +1,71 @@
+package goway
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Parámetro del path como int. Devuelve un CustomError 400 si no es un
+// número o no cabe en un int.
+func (c *GoWayContext) PathParamInt(key string) (int, error) {
+	n, err := c.parsePathInt(key, strconv.IntSize)
+	return int(n), err
+}
+
+// Parámetro del path como int64, con los mismos errores que PathParamInt
+func (c *GoWayContext) PathParamInt64(key string) (int64, error) {
+	return c.parsePathInt(key, 64)
+}
+
+func (c *GoWayContext) parsePathInt(key string, bitSize int) (int64, error) {
+	value := c.PathParam(key)
+	n, err := strconv.ParseInt(value, 10, bitSize)
+	if err != nil {
+		if errors.Is(err, strconv.ErrRange) {
+			return 0, NewCustomError(fmt.Sprintf("path parameter %q out of range", key), http.StatusBadRequest)
+		}
+		return 0, NewCustomError(fmt.Sprintf("path parameter %q must be an integer", key), http.StatusBadRequest)
+	}
+	return n, nil
+}
+
+// Parámetro del path como UUID en formato canónico (8-4-4-4-12), devuelto en
+// minúsculas. Devuelve un CustomError 400 si el formato no es válido.
+func (c *GoWayContext) PathParamUUID(key string) (string, error) {
+	value := strings.ToLower(c.PathParam(key))
+	if !isUUID(value) {
+		return "", NewCustomError(fmt.Sprintf("path parameter %q must be a UUID", key), http.StatusBadRequest)
+	}
+	return value, nil
+}
+
+func isUUID(s string) bool {
+	if len(s) != 36 {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		switch i {
+		case 8, 13, 18, 23:
+			if s[i] != '-' {
+				return false
+			}
+		default:
+			if !strings.ContainsRune("0123456789abcdef", rune(s[i])) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// Patrón de la ruta resuelta ("/users/:id" en vez de "/users/42"), útil
+// como etiqueta de métricas y logs. Vacío si ninguna ruta coincidió.
+func (c *GoWayContext) RoutePattern() string {
+	if rt := stateFrom(c.r).route; rt != nil {
+		return rt.pattern
+	}
+	return ""
+}
@@ -0,0 +1,144 @@
+package goway
+
+import (
+	"errors"
+	"net/http"
+	"runtime/debug"
+	"strings"
+	"sync"
+)
+
+var (
+	errPoolFull   = errors.New("goway: worker pool queue is full")
+	errPoolClosed = errors.New("goway: worker pool is closed")
+)
+
+// Grupo fijo de goroutines que ejecutan los manejadores, para limitar el
+// paralelismo de manejadores que consumen CPU. Se activa con SetWorkerPool.
+//
+// No ahorra goroutines: net/http sigue usando una por petición, que
+// espera a que el worker termine. Lo que aporta es un tope de manejadores
+// en ejecución a la vez y una cola con rechazo (503) cuando se llena.
+type WorkerPool struct {
+	jobs   chan func()
+	mu     sync.RWMutex
+	closed bool
+}
+
+// Crear un pool de workers goroutines con una cola de queue peticiones en
+// espera. Con la cola llena las peticiones reciben un 503.
+func NewWorkerPool(workers, queue int) *WorkerPool {
+	if workers <= 0 {
+		workers = 1
+	}
+	p := &WorkerPool{jobs: make(chan func(), max(queue, 0))}
+	for range workers {
+		go func() {
+			for job := range p.jobs {
+				job()
+			}
+		}()
+	}
+	return p
+}
+
+// Parar los workers cuando terminen los trabajos que ya están en cola. Las
+// peticiones que lleguen después ejecutan su manejador en su propia
+// goroutine, como sin pool.
+func (p *WorkerPool) Close() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !p.closed {
+		p.closed = true
+		close(p.jobs)
+	}
+}
+
+// Poner un trabajo en la cola
+func (p *WorkerPool) submit(job func()) error {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.closed {
+		return errPoolClosed
+	}
+	select {
+	case p.jobs <- job:
+		return nil
+	default:
+		return errPoolFull
+	}
+}
+
+// Ejecutar los manejadores en el pool en vez de en la goroutine de cada
+// petición. Los middlewares siguen en la goroutine de la petición, que espera
+// a que el manejador termine. No pasan por el pool las peticiones de
+// WebSocket (Upgrade) ni de Server-Sent Events, ni las rutas marcadas con
+// SkipWorkerPool, porque ocuparían un worker durante toda la conexión. Con
+// nil se vuelve a una goroutine por petición.
+//
+//	g.SetWorkerPool(goway.NewWorkerPool(runtime.NumCPU(), 100))
+func (g *GoWay) SetWorkerPool(p *WorkerPool) {
+	g.pool = p
+}
+
+// Opción de ruta para que su manejador no pase por el pool, por ejemplo en
+// respuestas en streaming
+func SkipWorkerPool() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			stateFrom(r).skipPool = true
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// Ejecutar el manejador, en el pool si hay uno y la petición lo admite
+func runHandler(handler GoWayHandlerFunc, w http.ResponseWriter, r *http.Request) {
+	st := stateFrom(r)
+	var p *WorkerPool
+	if st.g != nil {
+		p = st.g.pool
+	}
+	if p == nil || st.skipPool || longLived(r) {
+		handler(NewGoWayContext(w, r))
+		return
+	}
+	done := make(chan any, 1)
+	err := p.submit(func() {
+		// El panic se devuelve a la goroutine de la petición, donde está la
+		// recuperación, con la pila del worker
+		defer func() {
+			rec := recover()
+			switch rec.(type) {
+			case nil, *CustomError:
+			default:
+				if rec != http.ErrAbortHandler {
+					rec = &recoveredPanic{value: rec, stack: debug.Stack()}
+				}
+			}
+			done <- rec
+		}()
+		// El cliente se fue mientras esperaba en la cola
+		if r.Context().Err() != nil {
+			return
+		}
+		handler(NewGoWayContext(w, r))
+	})
+	switch err {
+	case errPoolClosed:
+		handler(NewGoWayContext(w, r))
+		return
+	case errPoolFull:
+		w.Header().Set("Retry-After", "1")
+		writeError(w, r, NewCustomError("Service Unavailable", http.StatusServiceUnavailable))
+		return
+	}
+	if rec := <-done; rec != nil {
+		panic(rec)
+	}
+}
+
+// Peticiones que mantienen la conexión abierta mucho tiempo
+func longLived(r *http.Request) bool {
+	return r.Header.Get("Upgrade") != "" || strings.Contains(r.Header.Get("Accept"), "text/event-stream")
+}
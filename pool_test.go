@@ -0,0 +1,102 @@
+package goway
+
+import (
+	"context"
+	"crypto/sha256"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func panickingPoolHandler(c *GoWayContext) {
+	panic("boom")
+}
+
+func TestWorkerPoolPanicKeepsWorkerStack(t *testing.T) {
+	pool := NewWorkerPool(1, 1)
+	defer pool.Close()
+	g := NewGoWay()
+	g.Debug = true
+	g.SetWorkerPool(pool)
+	g.GET("/panic", panickingPoolHandler)
+
+	rec := g.Test(http.MethodGet, "/panic", nil)
+	if rec.Code != http.StatusInternalServerError {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusInternalServerError)
+	}
+	if !strings.Contains(rec.Body.String(), "panickingPoolHandler") {
+		t.Errorf("debug stack does not include the panicking handler:\n%s", rec.Body.String())
+	}
+}
+
+func TestWorkerPoolSkipsCanceledRequests(t *testing.T) {
+	pool := NewWorkerPool(1, 1)
+	defer pool.Close()
+	g := NewGoWay()
+	g.SetWorkerPool(pool)
+	ran := false
+	g.GET("/work", func(c *GoWayContext) { ran = true })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	g.TestRequest(httptest.NewRequest(http.MethodGet, "/work", nil).WithContext(ctx))
+	if ran {
+		t.Error("handler ran for a request canceled while queued")
+	}
+}
+
+func TestWorkerPoolClosedRunsInline(t *testing.T) {
+	pool := NewWorkerPool(1, 1)
+	pool.Close()
+	g := NewGoWay()
+	g.SetWorkerPool(pool)
+	g.GET("/work", func(c *GoWayContext) { c.w.WriteHeader(http.StatusNoContent) })
+
+	if rec := g.Test(http.MethodGet, "/work", nil); rec.Code != http.StatusNoContent {
+		t.Errorf("status after Close = %d, want %d", rec.Code, http.StatusNoContent)
+	}
+}
+
+// Manejador que consume CPU, el caso para el que está pensado el pool
+func cpuHandler(c *GoWayContext) {
+	sum := sha256.Sum256([]byte(c.r.URL.Path))
+	for range 200 {
+		sum = sha256.Sum256(sum[:])
+	}
+	c.Data(http.StatusOK, "application/octet-stream", sum[:])
+}
+
+// Muchas peticiones concurrentes (64 por CPU) contra un manejador que
+// consume CPU, con una goroutine por petición y con un pool de NumCPU workers
+func BenchmarkWorkerPoolThroughput(b *testing.B) {
+	for _, bc := range []struct {
+		name string
+		pool bool
+	}{{"goroutine-per-request", false}, {"worker-pool", true}} {
+		b.Run(bc.name, func(b *testing.B) {
+			g := NewGoWay()
+			g.ResetMiddlewares()
+			g.Use(ErrorHandlingMiddleware)
+			if bc.pool {
+				pool := NewWorkerPool(runtime.NumCPU(), 64*runtime.GOMAXPROCS(0))
+				defer pool.Close()
+				g.SetWorkerPool(pool)
+			}
+			g.GET("/hash", cpuHandler)
+			b.SetParallelism(64)
+			b.ReportAllocs()
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					rec := httptest.NewRecorder()
+					g.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/hash", nil))
+					if rec.Code != http.StatusOK {
+						b.Errorf("status = %d", rec.Code)
+						return
+					}
+				}
+			})
+		})
+	}
+}
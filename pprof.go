@@ -0,0 +1,81 @@
+package goway
+
+import (
+	"net/http"
+	"net/http/pprof"
+	"net/url"
+	"strings"
+)
+
+// Adaptar un http.Handler al tipo de manejador de GoWay
+func wrapHandler(h http.Handler) GoWayHandlerFunc {
+	return func(c *GoWayContext) {
+		h.ServeHTTP(c.w, c.r)
+	}
+}
+
+// Montar los endpoints de net/http/pprof bajo prefix (por ejemplo
+// "/debug/pprof"). Pasan por los middlewares globales y además por mw, que
+// se puede usar para protegerlos con autenticación; con
+// PProfBypassMiddlewares solo pasan por mw. Solo se registran al llamar a
+// este método, así que basta con no llamarlo en producción.
+func (g *GoWay) EnablePProf(prefix string, mw ...func(http.Handler) http.Handler) {
+	handle := g.Handle
+	if g.PProfBypassMiddlewares {
+		handle = g.handleDirect
+	}
+	prefix = strings.TrimSuffix(prefix, "/")
+	index := pprofPath(prefix, http.HandlerFunc(pprof.Index))
+	// Los enlaces del índice son relativos: el router redirige el prefijo sin
+	// barra final al índice
+	handle(http.MethodGet, prefix+"/{$}", wrapHandler(index), mw...)
+	handle(http.MethodGet, prefix+"/cmdline", wrapHandler(http.HandlerFunc(pprof.Cmdline)), mw...)
+	handle(http.MethodGet, prefix+"/profile", wrapHandler(http.HandlerFunc(pprof.Profile)), mw...)
+	handle(http.MethodGet, prefix+"/symbol", wrapHandler(http.HandlerFunc(pprof.Symbol)), mw...)
+	handle(http.MethodPost, prefix+"/symbol", wrapHandler(http.HandlerFunc(pprof.Symbol)), mw...)
+	handle(http.MethodGet, prefix+"/trace", wrapHandler(http.HandlerFunc(pprof.Trace)), mw...)
+	// Perfiles con nombre: heap, goroutine, allocs, block, mutex, threadcreate
+	handle(http.MethodGet, prefix+"/:name", wrapHandler(index), mw...)
+}
+
+// Registrar una ruta que se atiende antes de la cadena de middlewares
+// globales. No aparece en RegisteredRoutes.
+func (g *GoWay) handleDirect(method, pattern string, handler GoWayHandlerFunc, middlewares ...func(http.Handler) http.Handler) {
+	if g.direct == nil {
+		g.direct = newNode()
+	}
+	g.direct.leaf(pattern).routes[method] = newRoute(method, pattern, handler, middlewares)
+}
+
+// Atender la petición si es de una ruta registrada con handleDirect
+func (g *GoWay) serveDirect(w http.ResponseWriter, r *http.Request) bool {
+	rt, params, _, exact := g.direct.match(r.Method, r.URL.Path)
+	if !exact {
+		if target, ok := slashTarget(g.direct, r); ok {
+			http.Redirect(w, r, target, http.StatusTemporaryRedirect)
+			return true
+		}
+	}
+	if rt == nil {
+		return false
+	}
+	for _, p := range params {
+		r.SetPathValue(p.key, p.value)
+	}
+	rt.h.ServeHTTP(w, r)
+	return true
+}
+
+// Quitar prefix del path y pasarle a h el que espera net/http/pprof, que
+// busca el nombre del perfil a partir de "/debug/pprof/"
+func pprofPath(prefix string, h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r2 := new(http.Request)
+		*r2 = *r
+		r2.URL = new(url.URL)
+		*r2.URL = *r.URL
+		r2.URL.Path = "/debug/pprof/" + strings.TrimPrefix(strings.TrimPrefix(r.URL.Path, prefix), "/")
+		r2.URL.RawPath = ""
+		h.ServeHTTP(w, r2)
+	})
+}
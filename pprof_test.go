@@ -0,0 +1,47 @@
+package goway
+
+import (
+	"net/http"
+	"testing"
+)
+
+func denyMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "denied", http.StatusForbidden)
+	})
+}
+
+func TestPProfPassesThroughGlobalMiddlewares(t *testing.T) {
+	g := NewGoWay()
+	g.Use(denyMiddleware)
+	g.EnablePProf("/debug/pprof")
+	if rec := g.Test(http.MethodGet, "/debug/pprof/cmdline", nil); rec.Code != http.StatusForbidden {
+		t.Errorf("status = %d, want %d from the global middleware", rec.Code, http.StatusForbidden)
+	}
+}
+
+func TestPProfBypassMiddlewares(t *testing.T) {
+	g := NewGoWay()
+	g.Use(denyMiddleware)
+	g.PProfBypassMiddlewares = true
+	g.EnablePProf("/debug/pprof", headerMiddleware("X-Auth", "checked"))
+
+	rec := g.Test(http.MethodGet, "/debug/pprof/cmdline", nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d without the global middlewares", rec.Code, http.StatusOK)
+	}
+	if rec.Header().Get("X-Auth") != "checked" {
+		t.Errorf("the middleware passed to EnablePProf did not run")
+	}
+	if rec := g.Test(http.MethodGet, "/debug/pprof/heap", nil); rec.Code != http.StatusOK {
+		t.Errorf("GET /debug/pprof/heap status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	rec = g.Test(http.MethodGet, "/debug/pprof", nil)
+	if rec.Code != http.StatusTemporaryRedirect || rec.Header().Get("Location") != "/debug/pprof/" {
+		t.Errorf("GET /debug/pprof = %d (Location %q), want redirect to /debug/pprof/", rec.Code, rec.Header().Get("Location"))
+	}
+	// El resto de rutas siguen pasando por los middlewares globales
+	if rec := g.Test(http.MethodGet, "/other", nil); rec.Code != http.StatusForbidden {
+		t.Errorf("GET /other status = %d, want %d", rec.Code, http.StatusForbidden)
+	}
+}
@@ -0,0 +1,32 @@
+package goway
+
+import "net/http"
+
+// Documento de error según RFC 7807
+type ProblemDetails struct {
+	Type     string `json:"type"`
+	Title    string `json:"title"`
+	Status   int    `json:"status"`
+	Detail   string `json:"detail,omitempty"`
+	Instance string `json:"instance,omitempty"`
+}
+
+// Construir el documento a partir de un CustomError
+func NewProblemDetails(customErr *CustomError, instance string) *ProblemDetails {
+	return &ProblemDetails{
+		Type:     "about:blank",
+		Title:    http.StatusText(customErr.StatusCode),
+		Status:   customErr.StatusCode,
+		Detail:   customErr.Message,
+		Instance: instance,
+	}
+}
+
+// Escribir el error como application/problem+json
+func writeProblem(w http.ResponseWriter, r *http.Request, customErr *CustomError) {
+	problem := NewProblemDetails(customErr, r.URL.Path)
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(problem.Status)
+	encodeJSON(w, r, problem)
+}
@@ -0,0 +1,89 @@
+package goway
+
+import (
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Middleware de ruta que declara los tipos de contenido que devuelve, en
+// orden de preferencia. Se usa para negociar el formato de las respuestas de
+// error y de Negotiate aunque el cliente no envíe Accept.
+//
+//	g.GET("/users", listUsers, goway.Produces("application/json"))
+func Produces(types ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			stateFrom(r).produces = types
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// Tipos declarados con Produces para la ruta actual
+func (c *GoWayContext) Produces() []string {
+	return stateFrom(c.r).produces
+}
+
+// Tipo preferido según Produces de la ruta (o text/html y application/json si
+// no se declaró) y la cabecera Accept
+func (c *GoWayContext) NegotiatedType() string {
+	offers := c.Produces()
+	if len(offers) == 0 {
+		offers = []string{"application/json", "text/html"}
+	}
+	return negotiateType(c.r, offers)
+}
+
+// Elegir el primer tipo ofrecido que acepte el cliente. Sin Accept, o si
+// ninguno encaja, se devuelve el primero ofrecido. Sin ofertas devuelve "".
+func negotiateType(r *http.Request, offers []string) string {
+	if len(offers) == 0 {
+		return ""
+	}
+	accept := r.Header.Get("Accept")
+	if accept == "" {
+		return offers[0]
+	}
+	type accepted struct {
+		mediaType string
+		q         float64
+	}
+	var types []accepted
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		q := 1.0
+		if v, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(v, 64); err != nil {
+				continue
+			}
+		}
+		if q > 0 {
+			types = append(types, accepted{mediaType, q})
+		}
+	}
+	sort.SliceStable(types, func(i, j int) bool { return types[i].q > types[j].q })
+	for _, t := range types {
+		for _, offer := range offers {
+			if mediaMatches(t.mediaType, offer) {
+				return offer
+			}
+		}
+	}
+	return offers[0]
+}
+
+func mediaMatches(accepted, offer string) bool {
+	if accepted == "*/*" || strings.EqualFold(accepted, offer) {
+		return true
+	}
+	if prefix, ok := strings.CutSuffix(accepted, "/*"); ok {
+		return strings.HasPrefix(offer, prefix+"/")
+	}
+	return false
+}
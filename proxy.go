@@ -0,0 +1,69 @@
+package goway
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// Cabeceras de un solo salto que no se reenvían (RFC 7230, sección 6.1)
+var hopHeaders = []string{
+	"Connection",
+	"Proxy-Connection",
+	"Keep-Alive",
+	"Proxy-Authenticate",
+	"Proxy-Authorization",
+	"Te",
+	"Trailer",
+	"Transfer-Encoding",
+	"Upgrade",
+}
+
+// Reenviar al cliente la respuesta de un servidor upstream: status,
+// cabeceras (sin las de un solo salto) y cuerpo en streaming. Si el cliente
+// se desconecta se cierra el cuerpo upstream y se deja de copiar. Cierra
+// siempre resp.Body.
+func (c *GoWayContext) Proxy(resp *http.Response) error {
+	defer resp.Body.Close()
+	stop := context.AfterFunc(c.r.Context(), func() { resp.Body.Close() })
+	defer stop()
+
+	h := c.w.Header()
+	for k, values := range resp.Header {
+		for _, v := range values {
+			h.Add(k, v)
+		}
+	}
+	// Las cabeceras nombradas en Connection también son de un solo salto
+	for _, v := range resp.Header.Values("Connection") {
+		for _, name := range strings.Split(v, ",") {
+			h.Del(strings.TrimSpace(name))
+		}
+	}
+	for _, name := range hopHeaders {
+		h.Del(name)
+	}
+	c.w.WriteHeader(resp.StatusCode)
+
+	// Enviar cada bloque en cuanto llega, para respuestas de larga duración
+	buf := make([]byte, 32*1024)
+	for {
+		n, err := resp.Body.Read(buf)
+		if n > 0 {
+			if _, werr := c.w.Write(buf[:n]); werr != nil {
+				return werr
+			}
+			c.Flush()
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			if ctxErr := c.r.Context().Err(); ctxErr != nil {
+				return ctxErr
+			}
+			return err
+		}
+	}
+}
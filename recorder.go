@@ -0,0 +1,105 @@
+package goway
+
+import (
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Petición guardada por RequestRecorderMiddleware
+type RequestRecord struct {
+	Method   string
+	Path     string
+	Status   int
+	Duration time.Duration
+	Time     time.Time
+}
+
+// Buffer circular de tamaño fijo con las últimas peticiones
+type requestRing struct {
+	mu      sync.RWMutex
+	records []RequestRecord
+	next    int
+	full    bool
+}
+
+func (r *requestRing) add(rec RequestRecord) {
+	r.mu.Lock()
+	r.records[r.next] = rec
+	r.next = (r.next + 1) % len(r.records)
+	if r.next == 0 {
+		r.full = true
+	}
+	r.mu.Unlock()
+}
+
+// Copia de las entradas, de la más antigua a la más reciente
+func (r *requestRing) snapshot() []RequestRecord {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if !r.full {
+		return append([]RequestRecord(nil), r.records[:r.next]...)
+	}
+	out := make([]RequestRecord, 0, len(r.records))
+	out = append(out, r.records[r.next:]...)
+	return append(out, r.records[:r.next]...)
+}
+
+// Middleware que guarda en memoria las últimas size peticiones (método,
+// path, status, duración y hora) para consultarlas con RecentRequests. El
+// buffer es uno por servidor: si se registra varias veces (por ejemplo en
+// varias rutas) todas deben usar el mismo size; una instancia con otro size
+// se avisa en el log y no guarda nada.
+func RequestRecorderMiddleware(size int) func(http.Handler) http.Handler {
+	if size <= 0 {
+		size = 1
+	}
+	var warnOnce sync.Once
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			g := goWayFrom(r)
+			if g == nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+			ring := g.requestRing(size)
+			if len(ring.records) != size {
+				warnOnce.Do(func() {
+					loggerFrom(r).Errorf("goway: RequestRecorderMiddleware(%d) ignored: already recording the last %d requests", size, len(ring.records))
+				})
+				next.ServeHTTP(w, r)
+				return
+			}
+			start := time.Now()
+			rw := newResponseWriter(w)
+			next.ServeHTTP(rw, r)
+			ring.add(RequestRecord{
+				Method:   r.Method,
+				Path:     r.URL.Path,
+				Status:   rw.Status(),
+				Duration: time.Since(start),
+				Time:     start,
+			})
+		})
+	}
+}
+
+// Buffer del servidor, creado con el tamaño del primer middleware que atiende
+// una petición
+func (g *GoWay) requestRing(size int) *requestRing {
+	if ring := g.recent.Load(); ring != nil {
+		return ring
+	}
+	g.recent.CompareAndSwap(nil, &requestRing{records: make([]RequestRecord, size)})
+	return g.recent.Load()
+}
+
+// Últimas peticiones guardadas por RequestRecorderMiddleware, de la más
+// antigua a la más reciente. Vacío si el middleware no está registrado.
+func (g *GoWay) RecentRequests() []RequestRecord {
+	ring := g.recent.Load()
+	if ring == nil {
+		return nil
+	}
+	return ring.snapshot()
+}
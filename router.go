@@ -0,0 +1,256 @@
+package goway
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Ruta registrada
+type route struct {
+	method      string
+	pattern     string
+	handler     GoWayHandlerFunc
+	middlewares []func(http.Handler) http.Handler // Middlewares propios de la ruta
+	h           http.Handler                      // Manejador con sus middlewares aplicados
+	any         bool                              // Registrada con Any
+	group       *Group                            // Grupo en el que se registró (nil si ninguno)
+	name        string                            // Nombre dado con NamedRoute
+}
+
+// Manejador de errores del grupo de la ruta; admite una ruta nil
+func (rt *route) errorHandler() ErrorHandlerFunc {
+	if rt == nil {
+		return nil
+	}
+	return rt.group.findErrorHandler()
+}
+
+// Parámetro capturado de la ruta
+type pathParam struct {
+	key   string
+	value string
+}
+
+// Nodo del árbol de rutas, un nivel por segmento del path.
+//
+// Prioridad al resolver un segmento: estático, parámetro con restricción,
+// parámetro libre y por último comodín. Si una rama no termina en una ruta
+// con el método pedido se prueba la siguiente, así "/users/profile" no cae
+// en "/users/:id(\d+)".
+//
+// Las restricciones se evalúan con regexp en cada petición que llega a ese
+// nodo, por lo que conviene usar expresiones simples. Cada expresión se
+// compila una sola vez y se comparte entre rutas.
+//
+// Como en http.ServeMux, "/users" y "/users/" son paths distintos y un
+// patrón terminado en "/" abarca todo lo que cuelga de él ("/" responde a
+// cualquier path), salvo que termine en "{$}".
+type node struct {
+	static   map[string]*node
+	params   []*node
+	catchAll *node
+	name     string
+	expr     string
+	re       *regexp.Regexp
+	routes   map[string]*route
+	subtree  bool // Comodín implícito de un patrón terminado en "/"
+}
+
+func newNode() *node {
+	return &node{static: make(map[string]*node)}
+}
+
+// Caché de expresiones compiladas
+var (
+	regexCacheMu sync.Mutex
+	regexCache   = make(map[string]*regexp.Regexp)
+)
+
+func compileConstraint(expr string) *regexp.Regexp {
+	regexCacheMu.Lock()
+	defer regexCacheMu.Unlock()
+	if re, ok := regexCache[expr]; ok {
+		return re
+	}
+	re := regexp.MustCompile("^(?:" + expr + ")$")
+	regexCache[expr] = re
+	return re
+}
+
+// Segmentos del path. La barra final da un último segmento vacío, así
+// "/users/" no es lo mismo que "/users"; "/" es un único segmento vacío.
+func splitPath(path string) []string {
+	return strings.Split(strings.TrimPrefix(path, "/"), "/")
+}
+
+// Interpretar un segmento: ":id", ":id(\d+)", "{id}", "{path...}" o "*path"
+func parseSegment(seg string) (name, expr string, param, catchAll bool) {
+	switch {
+	case strings.HasPrefix(seg, "*"):
+		return seg[1:], "", false, true
+	case strings.HasPrefix(seg, "{") && strings.HasSuffix(seg, "...}"):
+		return seg[1 : len(seg)-4], "", false, true
+	case strings.HasPrefix(seg, "{") && strings.HasSuffix(seg, "}"):
+		return seg[1 : len(seg)-1], "", true, false
+	case strings.HasPrefix(seg, ":"):
+		name = seg[1:]
+		if i := strings.IndexByte(name, '('); i >= 0 && strings.HasSuffix(name, ")") {
+			return name[:i], name[i+1 : len(name)-1], true, false
+		}
+		return name, "", true, false
+	}
+	return "", "", false, false
+}
+
+// Obtener (creándolo si hace falta) el nodo donde termina el patrón
+func (n *node) leaf(pattern string) *node {
+	leaf, err := n.insert(pattern)
+	if err != nil {
+		panic("goway: " + err.Error())
+	}
+	return leaf
+}
+
+// Como leaf, con un error si el patrón no es válido
+func (n *node) insert(pattern string) (*node, error) {
+	segs := splitPath(pattern)
+	for i, seg := range segs {
+		last := i == len(segs)-1
+		switch {
+		case seg == "{$}" && last:
+			// Marcador de ServeMux: solo el path con la barra final, sin lo que cuelga
+			seg = ""
+		case seg == "" && last:
+			// Patrón terminado en "/": todo lo que cuelga de él
+			if n.catchAll == nil {
+				n.catchAll = newNode()
+				n.catchAll.subtree = true
+			}
+			n = n.catchAll
+			continue
+		}
+		name, expr, param, catchAll := parseSegment(seg)
+		switch {
+		case catchAll:
+			if i != len(segs)-1 {
+				return nil, fmt.Errorf("catch-all must be the last segment in %s", pattern)
+			}
+			if n.catchAll == nil {
+				n.catchAll = newNode()
+				n.catchAll.name = name
+			}
+			n = n.catchAll
+		case param:
+			if expr != "" {
+				if _, err := regexp.Compile(expr); err != nil {
+					return nil, fmt.Errorf("invalid constraint in %s: %w", pattern, err)
+				}
+			}
+			n = n.paramChild(name, expr)
+		default:
+			child, ok := n.static[seg]
+			if !ok {
+				child = newNode()
+				n.static[seg] = child
+			}
+			n = child
+		}
+	}
+	if n.routes == nil {
+		n.routes = make(map[string]*route)
+	}
+	return n, nil
+}
+
+func (n *node) paramChild(name, expr string) *node {
+	for _, child := range n.params {
+		if child.name == name && child.expr == expr {
+			return child
+		}
+	}
+	child := newNode()
+	child.name = name
+	child.expr = expr
+	if expr != "" {
+		child.re = compileConstraint(expr)
+	}
+	n.params = append(n.params, child)
+	// Los parámetros con restricción se prueban antes que los libres
+	sort.SliceStable(n.params, func(i, j int) bool {
+		return n.params[i].re != nil && n.params[j].re == nil
+	})
+	return child
+}
+
+// Buscar la ruta para el método y path. Si el path existe pero no con ese
+// método devuelve los métodos permitidos.
+func (n *node) lookup(method, path string) (*route, []pathParam, []string) {
+	rt, params, allowed, _ := n.match(method, path)
+	return rt, params, allowed
+}
+
+// Como lookup, e indica además si la coincidencia es exacta: ningún comodín
+// ha capturado segmentos del path (ver slashRedirect)
+func (n *node) match(method, path string) (*route, []pathParam, []string, bool) {
+	var matched []*node
+	rt, params, exact := n.find(splitPath(path), nil, method, &matched)
+	if rt != nil {
+		return rt, params, nil, exact
+	}
+	seen := make(map[string]bool)
+	var allowed []string
+	for _, m := range matched {
+		for method := range m.routes {
+			if !seen[method] {
+				seen[method] = true
+				allowed = append(allowed, method)
+			}
+		}
+	}
+	sort.Strings(allowed)
+	return nil, nil, allowed, false
+}
+
+func (n *node) find(segs []string, params []pathParam, method string, matched *[]*node) (*route, []pathParam, bool) {
+	if len(segs) == 0 && len(n.routes) > 0 {
+		if rt := n.routes[method]; rt != nil {
+			return rt, params, true
+		}
+		*matched = append(*matched, n)
+	}
+	if len(segs) > 0 {
+		if child, ok := n.static[segs[0]]; ok {
+			if rt, p, exact := child.find(segs[1:], params, method, matched); rt != nil {
+				return rt, p, exact
+			}
+		}
+		for _, child := range n.params {
+			if segs[0] == "" {
+				// Un parámetro no captura un segmento vacío
+				break
+			}
+			if child.re != nil && !child.re.MatchString(segs[0]) {
+				continue
+			}
+			p := append(params, pathParam{child.name, segs[0]})
+			if rt, p, exact := child.find(segs[1:], p, method, matched); rt != nil {
+				return rt, p, exact
+			}
+		}
+	}
+	// "/static/" no abarca "/static": ese path se redirige (ver slashRedirect)
+	if c := n.catchAll; c != nil && len(c.routes) > 0 && (len(segs) > 0 || !c.subtree) {
+		if rt := c.routes[method]; rt != nil {
+			if c.name != "" {
+				params = append(params, pathParam{c.name, strings.Join(segs, "/")})
+			}
+			return rt, params, len(segs) == 0 || len(segs) == 1 && segs[0] == ""
+		}
+		*matched = append(*matched, c)
+	}
+	return nil, nil, false
+}
@@ -0,0 +1,60 @@
+package goway
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// El árbol de rutas debe resolver igual que el http.ServeMux que usaba
+// GoWay originalmente: paths con y sin barra final distintos, patrones
+// terminados en "/" como subárbol y "/" para cualquier path.
+func TestRoutingMatchesServeMux(t *testing.T) {
+	patterns := []string{"/", "/users", "/users/{$}", "/static/", "/items/{id}"}
+
+	mux := http.NewServeMux()
+	g := NewGoWay()
+	g.ResetMiddlewares()
+	for _, p := range patterns {
+		mux.HandleFunc("GET "+p, func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(p))
+		})
+		g.GET(p, func(c *GoWayContext) { c.Write([]byte(p)) })
+	}
+
+	paths := []string{
+		"/", "/anything/else", "/users", "/users/", "/users/42",
+		"/static", "/static?v=1", "/static/", "/static/css/app.css",
+		"/items/42", "/items/", "/items/42/",
+	}
+	for _, path := range paths {
+		want := httptest.NewRecorder()
+		mux.ServeHTTP(want, httptest.NewRequest(http.MethodGet, path, nil))
+		got := g.Test(http.MethodGet, path, nil)
+		if got.Code != want.Code || got.Body.String() != want.Body.String() || got.Header().Get("Location") != want.Header().Get("Location") {
+			t.Errorf("GET %s = %d %q (Location %q), ServeMux gives %d %q (Location %q)",
+				path, got.Code, got.Body.String(), got.Header().Get("Location"),
+				want.Code, want.Body.String(), want.Header().Get("Location"))
+		}
+	}
+}
+
+func TestTrailingSlashIsDistinct(t *testing.T) {
+	g := NewGoWay()
+	g.GET("/users", func(c *GoWayContext) { c.Write([]byte("list")) })
+	if rec := g.Test(http.MethodGet, "/users/", nil); rec.Code != http.StatusNotFound {
+		t.Errorf("GET /users/ status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+}
+
+func TestPProfIndexRedirect(t *testing.T) {
+	g := NewGoWay()
+	g.EnablePProf("/debug/pprof")
+	rec := g.Test(http.MethodGet, "/debug/pprof", nil)
+	if rec.Code != http.StatusTemporaryRedirect || rec.Header().Get("Location") != "/debug/pprof/" {
+		t.Fatalf("GET /debug/pprof = %d (Location %q), want redirect to /debug/pprof/", rec.Code, rec.Header().Get("Location"))
+	}
+	if rec := g.Test(http.MethodGet, "/debug/pprof/", nil); rec.Code != http.StatusOK {
+		t.Errorf("GET /debug/pprof/ status = %d, want %d", rec.Code, http.StatusOK)
+	}
+}
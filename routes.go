@@ -0,0 +1,104 @@
+package goway
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"runtime"
+	"strings"
+)
+
+// Definición de una ruta para registrar en bloque con Routes
+type RouteDef struct {
+	Method      string
+	Pattern     string
+	Handler     GoWayHandlerFunc
+	Middlewares []func(http.Handler) http.Handler
+}
+
+func (d RouteDef) validate() error {
+	switch {
+	case d.Method == "" || strings.ContainsAny(d.Method, " \t/"):
+		return fmt.Errorf("invalid method %q", d.Method)
+	case !strings.HasPrefix(d.Pattern, "/"):
+		return fmt.Errorf("pattern %q must start with /", d.Pattern)
+	case d.Handler == nil:
+		return fmt.Errorf("nil handler for %s %s", d.Method, d.Pattern)
+	}
+	return nil
+}
+
+// Registrar una tabla de rutas. Se validan todas antes de registrar
+// ninguna; si alguna no es válida (método o patrón incorrecto, comodín que no
+// es el último segmento, restricción que no compila o ruta duplicada, entre
+// ellas o con una ya registrada) se devuelve el error de la primera y no se
+// registra nada.
+func (g *GoWay) Routes(defs ...RouteDef) error {
+	// Las rutas se prueban en un árbol aparte con las ya registradas
+	scratch := newNode()
+	for _, rt := range g.routes {
+		if rt.any || rt.group != nil && rt.group.host != nil {
+			// Any cede ante una ruta explícita; las de Host van en su árbol
+			continue
+		}
+		scratch.leaf(rt.pattern).routes[rt.method] = rt
+	}
+	for i, d := range defs {
+		if err := d.validate(); err != nil {
+			return fmt.Errorf("goway: route %d: %w", i, err)
+		}
+		leaf, err := scratch.insert(d.Pattern)
+		if err != nil {
+			return fmt.Errorf("goway: route %d: %w", i, err)
+		}
+		method := strings.ToUpper(d.Method)
+		if prev := leaf.routes[method]; prev != nil {
+			return fmt.Errorf("goway: route %d: duplicate route %s %s (conflicts with %s %s)", i, method, d.Pattern, prev.method, prev.pattern)
+		}
+		leaf.routes[method] = &route{method: method, pattern: d.Pattern}
+	}
+	for _, d := range defs {
+		g.Handle(strings.ToUpper(d.Method), d.Pattern, d.Handler, d.Middlewares...)
+	}
+	return nil
+}
+
+// Datos de una ruta registrada, para inspeccionar la tabla de rutas
+type RouteInfo struct {
+	Method      string
+	Pattern     string
+	Host        string // Patrón de host si se registró con Host
+	Name        string // Nombre dado con NamedRoute
+	Handler     string // Nombre de la función manejadora
+	Middlewares int    // Middlewares de la ruta, incluidos los de su grupo
+}
+
+// Rutas registradas en orden de registro
+func (g *GoWay) RegisteredRoutes() []RouteInfo {
+	infos := make([]RouteInfo, 0, len(g.routes))
+	for _, rt := range g.routes {
+		info := RouteInfo{
+			Method:      rt.method,
+			Pattern:     rt.pattern,
+			Name:        rt.name,
+			Handler:     funcName(rt.handler),
+			Middlewares: len(rt.middlewares),
+		}
+		if rt.group != nil && rt.group.host != nil {
+			info.Host = rt.group.host.pattern
+		}
+		infos = append(infos, info)
+	}
+	return infos
+}
+
+func funcName(fn any) string {
+	v := reflect.ValueOf(fn)
+	if v.Kind() != reflect.Func || v.IsNil() {
+		return ""
+	}
+	if f := runtime.FuncForPC(v.Pointer()); f != nil {
+		return f.Name()
+	}
+	return ""
+}
@@ -0,0 +1,62 @@
+package goway
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestRoutesRejectsInvalidTable(t *testing.T) {
+	ok := func(c *GoWayContext) { c.w.WriteHeader(http.StatusNoContent) }
+	tests := []struct {
+		name string
+		defs []RouteDef
+		want string
+	}{
+		{"duplicate", []RouteDef{
+			{Method: "GET", Pattern: "/items", Handler: ok},
+			{Method: "get", Pattern: "/items", Handler: ok},
+		}, "route 1: duplicate route GET /items"},
+		{"duplicate of registered route", []RouteDef{
+			{Method: "GET", Pattern: "/items", Handler: ok},
+			{Method: "GET", Pattern: "/health", Handler: ok},
+		}, "route 1: duplicate route GET /health"},
+		{"catch-all not last", []RouteDef{
+			{Method: "GET", Pattern: "/items", Handler: ok},
+			{Method: "GET", Pattern: "/files/*path/raw", Handler: ok},
+		}, "route 1: catch-all must be the last segment"},
+		{"invalid constraint", []RouteDef{
+			{Method: "GET", Pattern: "/items", Handler: ok},
+			{Method: "GET", Pattern: "/items/:id([0-9)", Handler: ok},
+		}, "route 1: invalid constraint"},
+		{"nil handler", []RouteDef{
+			{Method: "GET", Pattern: "/items", Handler: ok},
+			{Method: "GET", Pattern: "/orders"},
+		}, "route 1: nil handler"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGoWay()
+			g.GET("/health", ok)
+			err := g.Routes(tt.defs...)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("Routes error = %v, want %q", err, tt.want)
+			}
+			if n := len(g.RegisteredRoutes()); n != 1 {
+				t.Errorf("%d routes registered after the error, want only /health", n)
+			}
+			if rec := g.Test(http.MethodGet, "/items", nil); rec.Code != http.StatusNotFound {
+				t.Errorf("GET /items status = %d, want %d", rec.Code, http.StatusNotFound)
+			}
+		})
+	}
+}
+
+func TestRoutesAllowsReplacingAny(t *testing.T) {
+	ok := func(c *GoWayContext) { c.w.WriteHeader(http.StatusNoContent) }
+	g := NewGoWay()
+	g.Any("/items", ok)
+	if err := g.Routes(RouteDef{Method: "GET", Pattern: "/items", Handler: ok}); err != nil {
+		t.Fatalf("Routes error = %v, want the explicit route to replace Any", err)
+	}
+}
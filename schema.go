@@ -0,0 +1,169 @@
+package goway
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+// Detalle de un fallo de validación contra el esquema
+type SchemaViolation struct {
+	Path    string `json:"path"`    // Ubicación en el cuerpo (JSON pointer)
+	Message string `json:"message"` // Descripción del fallo
+}
+
+// Respuesta 400 cuando el cuerpo no cumple el esquema
+type schemaErrorResponse struct {
+	Error   string            `json:"error"`
+	Details []SchemaViolation `json:"details"`
+}
+
+// Compilar un esquema JSON (draft 2020-12 por defecto)
+func compileSchema(schema []byte) (*jsonschema.Schema, error) {
+	compiler := jsonschema.NewCompiler()
+	if err := compiler.AddResource("schema.json", bytes.NewReader(schema)); err != nil {
+		return nil, err
+	}
+	return compiler.Compile("schema.json")
+}
+
+// Registrar un esquema JSON para el cuerpo de las peticiones a method y
+// pattern (el mismo patrón usado al registrar la ruta). Después de los
+// middlewares de la ruta y antes de ejecutar el manejador se valida el cuerpo
+// y, si no cumple, se responde 400 con la lista de fallos. Las rutas sin
+// esquema no se validan.
+func (g *GoWay) Validate(method, pattern string, schema []byte) error {
+	sch, err := compileSchema(schema)
+	if err != nil {
+		return fmt.Errorf("goway: invalid schema for %s %s: %w", method, pattern, err)
+	}
+	if g.schemas == nil {
+		g.schemas = make(map[string]*jsonschema.Schema)
+	}
+	g.schemas[method+" "+pattern] = sch
+	return nil
+}
+
+// Validar el cuerpo contra el esquema de la ruta. Devuelve false si ya se
+// respondió con un error. El cuerpo se deja disponible para el manejador.
+func (g *GoWay) validateRequest(w http.ResponseWriter, r *http.Request, rt *route) bool {
+	sch := g.schemas[rt.method+" "+rt.pattern]
+	if sch == nil {
+		return true
+	}
+	c := NewGoWayContext(w, r)
+	if err := c.limitBody(); err != nil {
+		writeError(w, r, err.(*CustomError))
+		return false
+	}
+	body, err := io.ReadAll(r.Body)
+	r.Body.Close()
+	if err != nil {
+		if customErr, ok := bodyError(err).(*CustomError); ok {
+			writeError(w, r, customErr)
+		} else {
+			writeError(w, r, NewCustomError("Bad Request", http.StatusBadRequest))
+		}
+		return false
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+
+	if violations := validateJSON(sch, body); violations != nil {
+		writeSchemaError(w, r, violations)
+		return false
+	}
+	return true
+}
+
+// Responder 400 con la lista de fallos de validación
+func writeSchemaError(w http.ResponseWriter, r *http.Request, violations []SchemaViolation) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusBadRequest)
+	encodeJSON(w, r, schemaErrorResponse{Error: "request body does not match schema", Details: violations})
+}
+
+// Error de BindSchema cuando el cuerpo no cumple el esquema. Devuelto desde un
+// manejador con WithError se responde 400 con la lista de fallos.
+type SchemaError struct {
+	Violations []SchemaViolation
+}
+
+func (e *SchemaError) Error() string {
+	if len(e.Violations) == 0 {
+		return "request body does not match schema"
+	}
+	return fmt.Sprintf("request body does not match schema: %s: %s", e.Violations[0].Path, e.Violations[0].Message)
+}
+
+// Esquemas de BindSchema ya compilados, por hash del texto
+var inlineSchemas sync.Map // [sha256.Size]byte -> *jsonschema.Schema
+
+// Validar el cuerpo JSON contra schema y, si lo cumple, decodificarlo en v.
+// Si no lo cumple devuelve un *SchemaError con los fallos. Cada esquema
+// distinto se compila una sola vez, así que sirve para validar cargas
+// distintas según el origen sin registrarlas al arrancar.
+func (c *GoWayContext) BindSchema(schema []byte, v any) error {
+	key := sha256.Sum256(schema)
+	var sch *jsonschema.Schema
+	if cached, ok := inlineSchemas.Load(key); ok {
+		sch = cached.(*jsonschema.Schema)
+	} else {
+		compiled, err := compileSchema(schema)
+		if err != nil {
+			return fmt.Errorf("goway: invalid schema: %w", err)
+		}
+		inlineSchemas.Store(key, compiled)
+		sch = compiled
+	}
+	if err := c.limitBody(); err != nil {
+		return err
+	}
+	body, err := io.ReadAll(c.r.Body)
+	c.r.Body.Close()
+	if err != nil {
+		return bodyError(err)
+	}
+	if err := checkJSONDepth(body, maxJSONDepth(c.r)); err != nil {
+		return err
+	}
+	if violations := validateJSON(sch, body); violations != nil {
+		return &SchemaError{Violations: violations}
+	}
+	return decodeJSONBody(c.r, body, v)
+}
+
+// Validar el JSON y devolver los fallos, o nil si es válido
+func validateJSON(sch *jsonschema.Schema, body []byte) []SchemaViolation {
+	var doc any
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	if err := dec.Decode(&doc); err != nil {
+		return []SchemaViolation{{Path: "", Message: "invalid JSON: " + err.Error()}}
+	}
+	err := sch.Validate(doc)
+	if err == nil {
+		return nil
+	}
+	verr, ok := err.(*jsonschema.ValidationError)
+	if !ok {
+		return []SchemaViolation{{Message: err.Error()}}
+	}
+	var violations []SchemaViolation
+	for _, unit := range verr.BasicOutput().Errors {
+		// Las entradas sin mensaje propio solo agrupan a otras
+		if unit.Error == "" || unit.KeywordLocation == "" {
+			continue
+		}
+		violations = append(violations, SchemaViolation{Path: unit.InstanceLocation, Message: unit.Error})
+	}
+	if len(violations) == 0 {
+		violations = []SchemaViolation{{Path: verr.InstanceLocation, Message: verr.Message}}
+	}
+	return violations
+}
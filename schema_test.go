@@ -0,0 +1,41 @@
+package goway
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestValidateRunsAfterRouteMiddlewares(t *testing.T) {
+	g := NewGoWay()
+	auth := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Authorization") == "" {
+				http.Error(w, "unauthorized", http.StatusUnauthorized)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+	g.POST("/users", func(c *GoWayContext) { c.w.WriteHeader(http.StatusCreated) }, auth)
+	if err := g.Validate(http.MethodPost, "/users", []byte(`{"type":"object","required":["name"]}`)); err != nil {
+		t.Fatal(err)
+	}
+
+	// Sin autenticación el cuerpo no llega a validarse
+	if rec := g.Test(http.MethodPost, "/users", strings.NewReader(`{}`)); rec.Code != http.StatusUnauthorized {
+		t.Errorf("unauthenticated status = %d, want %d", rec.Code, http.StatusUnauthorized)
+	}
+
+	r := func(body string) *http.Request {
+		req, _ := http.NewRequest(http.MethodPost, "/users", strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer token")
+		return req
+	}
+	if rec := g.TestRequest(r(`{}`)); rec.Code != http.StatusBadRequest {
+		t.Errorf("invalid body status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+	if rec := g.TestRequest(r(`{"name":"ada"}`)); rec.Code != http.StatusCreated {
+		t.Errorf("valid body status = %d, want %d", rec.Code, http.StatusCreated)
+	}
+}
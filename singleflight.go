@@ -0,0 +1,92 @@
+package goway
+
+import (
+	"net/http"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// Middleware que agrupa peticiones idénticas concurrentes: mientras una se
+// está atendiendo, las que llegan con la misma clave esperan y reciben una
+// copia de su respuesta en vez de ejecutar otra vez el manejador. Solo se
+// aplica a GET y HEAD. keyFn devuelve "" para no agrupar una petición, por
+// ejemplo si la respuesta depende del usuario. Las copias no llevan las
+// cabeceras Set-Cookie de la respuesta original.
+//
+//	g.GET("/report", report, goway.SingleflightMiddleware(func(r *http.Request) string {
+//		return r.URL.RequestURI()
+//	}))
+func SingleflightMiddleware(keyFn func(r *http.Request) string) func(http.Handler) http.Handler {
+	var group singleflight.Group
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodGet && r.Method != http.MethodHead {
+				next.ServeHTTP(w, r)
+				return
+			}
+			key := keyFn(r)
+			if key == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+			v, leader := doFlight(&group, r.Method+" "+key, func() any {
+				rec := &recordWriter{ResponseWriter: w}
+				next.ServeHTTP(rec, r)
+				header := w.Header().Clone()
+				// Las cookies son del cliente que hizo la petición, no de los que esperan
+				header.Del("Set-Cookie")
+				return &cachedResponse{
+					status: rec.status,
+					header: header,
+					body:   rec.body.Bytes(),
+				}
+			})
+			if leader {
+				// La respuesta ya se envió mientras se grababa
+				return
+			}
+			resp := v.(*cachedResponse)
+			for k, vals := range resp.header {
+				w.Header()[k] = vals
+			}
+			if resp.status != 0 {
+				w.WriteHeader(resp.status)
+			}
+			w.Write(resp.body)
+		})
+	}
+}
+
+// Resultado de una llamada a group.Do, con el panic del manejador si lo hubo
+type flightResult struct {
+	value    any
+	panicked bool
+}
+
+// Ejecutar fn con group.Do. singleflight envuelve los panics en su propio
+// error, y panic(NewCustomError(..., 404)) o http.ErrAbortHandler acabarían
+// en un 500: el panic se recupera dentro de Do y se vuelve a lanzar con el
+// valor original, en el líder y en los que esperan.
+func doFlight(group *singleflight.Group, key string, fn func() any) (v any, leader bool) {
+	res, _, _ := group.Do(key, func() (any, error) {
+		leader = true
+		return runFlight(fn), nil
+	})
+	r := res.(flightResult)
+	if r.panicked {
+		panic(r.value)
+	}
+	return r.value, leader
+}
+
+func runFlight(fn func() any) (res flightResult) {
+	defer func() {
+		if res.panicked {
+			res.value = recover()
+		}
+	}()
+	res.panicked = true
+	res.value = fn()
+	res.panicked = false
+	return res
+}
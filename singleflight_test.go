@@ -0,0 +1,47 @@
+package goway
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestSingleflightKeepsLeaderPanic(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	var once sync.Once
+	g := NewGoWay()
+	g.GET("/report", func(c *GoWayContext) {
+		once.Do(func() { close(started) })
+		<-release
+		panic(NewCustomError("report not found", http.StatusNotFound))
+	}, SingleflightMiddleware(func(r *http.Request) string { return r.URL.Path }))
+
+	codes := make(chan int, 2)
+	get := func() { codes <- g.Test(http.MethodGet, "/report", nil).Code }
+	go get()
+	<-started
+	go get()
+	// Dar tiempo a la segunda petición para esperar a la primera
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+
+	for range 2 {
+		if code := <-codes; code != http.StatusNotFound {
+			t.Errorf("status = %d, want %d from the CustomError panic", code, http.StatusNotFound)
+		}
+	}
+}
+
+func TestSingleflightKeepsAbortHandler(t *testing.T) {
+	h := SingleflightMiddleware(func(r *http.Request) string { return r.URL.Path })(
+		http.HandlerFunc(func(http.ResponseWriter, *http.Request) { panic(http.ErrAbortHandler) }))
+	defer func() {
+		if rec := recover(); rec != http.ErrAbortHandler {
+			t.Fatalf("recovered %v, want http.ErrAbortHandler", rec)
+		}
+	}()
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/report", nil))
+}
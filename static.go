@@ -0,0 +1,74 @@
+package goway
+
+import (
+	"io"
+	"io/fs"
+	"net/http"
+	"path"
+	"strings"
+)
+
+// Servir archivos de cualquier fs.FS (por ejemplo un embed.FS) bajo urlPrefix.
+// Las rutas desconocidas bajo el prefijo devuelven index.html para que el
+// router del frontend las resuelva.
+func (g *GoWay) StaticFS(urlPrefix string, fsys fs.FS) {
+	prefix := strings.TrimSuffix(urlPrefix, "/")
+	g.GET(prefix+"/*filepath", func(c *GoWayContext) {
+		// path.Clean sobre un path absoluto elimina cualquier ".." que intente salir de fsys
+		name := strings.TrimPrefix(path.Clean("/"+c.PathParam("filepath")), "/")
+		if name == "" || !fs.ValidPath(name) {
+			name = "index.html"
+		}
+		if info, err := fs.Stat(fsys, name); err != nil || info.IsDir() {
+			name = "index.html"
+		}
+		http.ServeFileFS(c.w, c.r, fsys, name)
+	})
+}
+
+// Responder con indexPath a cualquier GET sin ruta registrada, para apps con
+// router en el cliente. No aplica a paths con extensión (assets que faltan) ni
+// a los que empiezan por alguno de apiPrefixes; esos siguen devolviendo 404.
+func (g *GoWay) SPAFallback(indexPath string, apiPrefixes ...string) {
+	g.notFound = func(c *GoWayContext) {
+		p := c.r.URL.Path
+		if c.r.Method != http.MethodGet && c.r.Method != http.MethodHead || path.Ext(p) != "" {
+			http.NotFound(c.w, c.r)
+			return
+		}
+		for _, prefix := range apiPrefixes {
+			if strings.HasPrefix(p, prefix) {
+				http.NotFound(c.w, c.r)
+				return
+			}
+		}
+		http.ServeFile(c.w, c.r, indexPath)
+	}
+}
+
+// Servir /favicon.ico desde el fichero indicado, sin registrar las
+// peticiones en el log de acceso
+func (g *GoWay) Favicon(file string) {
+	g.quietPath("/favicon.ico")
+	g.GET("/favicon.ico", func(c *GoWayContext) {
+		http.ServeFile(c.w, c.r, file)
+	})
+}
+
+// Servir /robots.txt con el contenido indicado, sin registrar las
+// peticiones en el log de acceso
+func (g *GoWay) Robots(content string) {
+	g.quietPath("/robots.txt")
+	g.GET("/robots.txt", func(c *GoWayContext) {
+		c.w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		c.w.WriteHeader(http.StatusOK)
+		io.WriteString(c.w, content)
+	})
+}
+
+func (g *GoWay) quietPath(p string) {
+	if g.quietPaths == nil {
+		g.quietPaths = make(map[string]bool)
+	}
+	g.quietPaths[p] = true
+}
@@ -0,0 +1,48 @@
+package goway
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"html/template"
+	"sync"
+)
+
+// Plantillas de HTMLTemplate ya interpretadas, por hash del texto
+var inlineTemplates sync.Map // [sha256.Size]byte -> *template.Template
+
+// Responder con HTML generado a partir de una plantilla en texto, con los
+// datos escapados por html/template. Pensado para páginas pequeñas que no
+// merecen un fichero. Cada plantilla distinta se interpreta una sola vez, así
+// que el texto debe ser fijo (una constante) y no construirse por petición.
+// Si la plantilla no es válida o falla al ejecutarse se responde con el
+// manejador de errores.
+//
+//	c.HTMLTemplate(http.StatusOK, `<h1>Hola {{.Name}}</h1>`, user)
+func (c *GoWayContext) HTMLTemplate(status int, tmpl string, data any) {
+	t, err := inlineTemplate(tmpl)
+	if err != nil {
+		writeError(c.w, c.r, internalError(c.r, err))
+		return
+	}
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, data); err != nil {
+		writeError(c.w, c.r, internalError(c.r, err))
+		return
+	}
+	c.w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	c.w.WriteHeader(status)
+	c.w.Write(buf.Bytes())
+}
+
+func inlineTemplate(text string) (*template.Template, error) {
+	key := sha256.Sum256([]byte(text))
+	if t, ok := inlineTemplates.Load(key); ok {
+		return t.(*template.Template), nil
+	}
+	t, err := template.New("inline").Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	inlineTemplates.Store(key, t)
+	return t, nil
+}
@@ -0,0 +1,41 @@
+package goway
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+)
+
+// Ejecutar una petición por toda la cadena de middlewares y el router y
+// devolver la respuesta grabada. Pensado para tests.
+func (g *GoWay) Test(method, target string, body io.Reader) *httptest.ResponseRecorder {
+	return g.TestRequest(httptest.NewRequest(method, target, body))
+}
+
+// Igual que Test pero con una petición ya construida (cabeceras, cookies...)
+func (g *GoWay) TestRequest(r *http.Request) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	g.ServeHTTP(rec, r)
+	return rec
+}
+
+// Igual que Test enviando v codificado como JSON, con Content-Type
+// application/json y las cabeceras adicionales indicadas
+func (g *GoWay) TestJSON(method, target string, v any, headers ...http.Header) *httptest.ResponseRecorder {
+	data, err := json.Marshal(v)
+	if err != nil {
+		panic("goway: TestJSON: " + err.Error())
+	}
+	r := httptest.NewRequest(method, target, bytes.NewReader(data))
+	r.Header.Set("Content-Type", "application/json")
+	for _, h := range headers {
+		for k, values := range h {
+			for _, value := range values {
+				r.Header.Add(k, value)
+			}
+		}
+	}
+	return g.TestRequest(r)
+}
@@ -0,0 +1,72 @@
+package goway
+
+import (
+	"net/http"
+	"time"
+)
+
+// Tiempo propio de un middleware global en una petición, sin contar lo que
+// tardaron los siguientes middlewares ni el manejador
+type MiddlewareTiming struct {
+	Name     string
+	Duration time.Duration
+}
+
+// Tiempos de los middlewares en una petición. Cada tiempo incluye el de los
+// middlewares siguientes.
+type timingProfile struct {
+	names []string
+	times []time.Duration
+}
+
+// Tiempos de cada middleware global en esta petición, en orden de ejecución,
+// más el router y el manejador al final. Requiere ProfileMiddlewares; sin él
+// devuelve nil. Los middlewares que aún no terminaron aparecen con 0, así que
+// para verlos todos hay que leerlos en AfterResponse.
+func (c *GoWayContext) MiddlewareTimings() []MiddlewareTiming {
+	p := stateFrom(c.r).timings
+	if p == nil {
+		return nil
+	}
+	out := make([]MiddlewareTiming, len(p.names))
+	for i, name := range p.names {
+		own := p.times[i]
+		if i+1 < len(p.times) && p.times[i+1] <= own {
+			own -= p.times[i+1]
+		}
+		out[i] = MiddlewareTiming{Name: name, Duration: own}
+	}
+	return out
+}
+
+// Cadena que mide cuánto tarda cada middleware, incluyendo los siguientes.
+// El tiempo propio se calcula restando el del siguiente en MiddlewareTimings.
+func (g *GoWay) timedChain(final http.Handler) http.Handler {
+	n := len(g.middlewares)
+	names := make([]string, n+1)
+	for i, mw := range g.middlewares {
+		names[i] = funcName(mw)
+	}
+	names[n] = "router"
+
+	h := timed(n, final)
+	for i := n - 1; i >= 0; i-- {
+		h = timed(i, g.middlewares[i](h))
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		stateFrom(r).timings = &timingProfile{names: names, times: make([]time.Duration, n+1)}
+		h.ServeHTTP(w, r)
+	})
+}
+
+func timed(i int, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		defer func() {
+			if p := stateFrom(r).timings; p != nil {
+				p.times[i] = time.Since(start)
+			}
+		}()
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,40 @@
+package goway
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+)
+
+// Ejecutar el servidor con HTTPS. El certificado se entrega mediante
+// GetCertificate, así que puede sustituirse en caliente con
+// ReloadCertificate sin reiniciar ni cortar conexiones.
+func (g *GoWay) RunTLS(addr, certFile, keyFile string, ctx context.Context) error {
+	if err := g.ReloadCertificate(certFile, keyFile); err != nil {
+		return err
+	}
+	tlsConfig := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		NextProtos: []string{"h2", "http/1.1"},
+		GetCertificate: func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+			cert := g.cert.Load()
+			if cert == nil {
+				return nil, errors.New("goway: no TLS certificate loaded")
+			}
+			return cert, nil
+		},
+	}
+	return g.run(ctx, addr, tlsConfig)
+}
+
+// Cargar un certificado nuevo (por ejemplo tras una renovación de Let's
+// Encrypt) y usarlo en las conexiones nuevas. Las existentes siguen con el
+// anterior. Si los ficheros no son válidos se mantiene el certificado actual.
+func (g *GoWay) ReloadCertificate(certFile, keyFile string) error {
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return err
+	}
+	g.cert.Store(&cert)
+	return nil
+}
@@ -0,0 +1,58 @@
+package goway
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+)
+
+// Copiar el cuerpo de la petición a dst sin cargarlo en memoria. Si supera
+// maxBytes (o falla la copia) se borra el fichero parcial; el exceso de
+// tamaño se devuelve como CustomError 413. Devuelve los bytes escritos.
+func (c *GoWayContext) SaveBodyToFile(dst string, maxBytes int64) (int64, error) {
+	body := http.MaxBytesReader(c.w, c.r.Body, maxBytes)
+	defer body.Close()
+
+	f, err := os.Create(dst)
+	if err != nil {
+		return 0, err
+	}
+	n, err := io.Copy(f, body)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(dst)
+		return n, bodyError(err)
+	}
+	return n, nil
+}
+
+// Aplicar MaxBodySize antes de leer el cuerpo. Si Content-Length ya lo supera
+// se rechaza sin leer nada; si no se conoce (chunked) se limita la lectura.
+func (c *GoWayContext) limitBody() error {
+	g := goWayFrom(c.r)
+	if g == nil || g.MaxBodySize <= 0 || c.r.Body == nil {
+		return nil
+	}
+	if c.r.ContentLength > g.MaxBodySize {
+		return tooLarge(g.MaxBodySize)
+	}
+	c.r.Body = http.MaxBytesReader(c.w, c.r.Body, g.MaxBodySize)
+	return nil
+}
+
+// Convertir el error de MaxBytesReader en un 413
+func bodyError(err error) error {
+	var maxErr *http.MaxBytesError
+	if errors.As(err, &maxErr) {
+		return tooLarge(maxErr.Limit)
+	}
+	return err
+}
+
+func tooLarge(limit int64) *CustomError {
+	return NewCustomError(fmt.Sprintf("request body exceeds %d bytes", limit), http.StatusRequestEntityTooLarge)
+}
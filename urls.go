@@ -0,0 +1,66 @@
+package goway
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Registrar una ruta con nombre para poder construir su URL con URL
+func (g *GoWay) NamedRoute(name, method, pattern string, handler GoWayHandlerFunc, middlewares ...func(http.Handler) http.Handler) {
+	if _, ok := g.names[name]; ok {
+		panic(fmt.Sprintf("goway: duplicate route name %q", name))
+	}
+	rt := newRoute(method, pattern, handler, middlewares)
+	rt.name = name
+	g.addRoute(rt)
+	if g.names == nil {
+		g.names = make(map[string]*route)
+	}
+	g.names[name] = rt
+}
+
+// Construir el path de la ruta con nombre sustituyendo sus parámetros, en
+// orden, por params. Devuelve error si el nombre no existe, si sobran o
+// faltan parámetros o si un valor no cumple la restricción del segmento.
+//
+//	g.NamedRoute("user", "GET", "/users/:id(\\d+)", showUser)
+//	g.URL("user", "42") // "/users/42"
+func (g *GoWay) URL(name string, params ...string) (string, error) {
+	rt, ok := g.names[name]
+	if !ok {
+		return "", fmt.Errorf("goway: unknown route name %q", name)
+	}
+	segs := splitPath(rt.pattern)
+	out := make([]string, 0, len(segs))
+	i := 0
+	for _, seg := range segs {
+		if seg == "{$}" {
+			out = append(out, "")
+			continue
+		}
+		key, expr, param, catchAll := parseSegment(seg)
+		if !param && !catchAll {
+			out = append(out, seg)
+			continue
+		}
+		if i >= len(params) {
+			return "", fmt.Errorf("goway: missing value for parameter %q of route %q", key, name)
+		}
+		value := params[i]
+		i++
+		if expr != "" && !compileConstraint(expr).MatchString(value) {
+			return "", fmt.Errorf("goway: value %q does not match constraint of parameter %q in route %q", value, key, name)
+		}
+		if catchAll {
+			out = append(out, value)
+		} else {
+			out = append(out, url.PathEscape(value))
+		}
+	}
+	if i < len(params) {
+		return "", fmt.Errorf("goway: too many parameters for route %q", name)
+	}
+	return "/" + strings.Join(out, "/"), nil
+}
@@ -0,0 +1,199 @@
+package goway
+
+import (
+	"bufio"
+	"bytes"
+	"net"
+	"net/http"
+)
+
+// Tomar la conexión del writer original para protocolos como WebSocket.
+// Las librerías comprueban http.Hijacker directamente, así que cada wrapper
+// delega aquí.
+func hijack(w http.ResponseWriter) (net.Conn, *bufio.ReadWriter, error) {
+	return http.NewResponseController(w).Hijack()
+}
+
+// ResponseWriter que ejecuta una función justo antes de enviar las cabeceras,
+// cuando el manejador ya tuvo ocasión de modificarlas
+type hookWriter struct {
+	http.ResponseWriter
+	before func(h http.Header)
+	done   bool
+}
+
+func (w *hookWriter) run() {
+	if !w.done {
+		w.done = true
+		w.before(w.Header())
+	}
+}
+
+func (w *hookWriter) WriteHeader(code int) {
+	w.run()
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *hookWriter) Write(b []byte) (int, error) {
+	w.run()
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *hookWriter) Flush() {
+	w.run()
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Permite a http.ResponseController llegar al writer original
+func (w *hookWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func (w *hookWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return hijack(w.ResponseWriter)
+}
+
+// ResponseWriter que recuerda el status y los bytes enviados, para saber si
+// la respuesta ya empezó a escribirse
+type responseWriter struct {
+	http.ResponseWriter
+	status      int
+	size        int64
+	wroteHeader bool
+	hijacked    bool // La conexión pasó a otro protocolo (WebSocket...)
+	closed      bool // Respuesta terminada con Error: se descarta lo que venga después
+}
+
+// Reutilizar el wrapper si w ya lo es, para que todos compartan el mismo estado
+func newResponseWriter(w http.ResponseWriter) *responseWriter {
+	if rw, ok := w.(*responseWriter); ok {
+		return rw
+	}
+	return &responseWriter{ResponseWriter: w}
+}
+
+func (w *responseWriter) WriteHeader(code int) {
+	if w.wroteHeader {
+		return
+	}
+	// Las respuestas informativas (1xx) no cuentan como respuesta final
+	if code >= 200 || code == http.StatusSwitchingProtocols {
+		w.status = code
+		w.wroteHeader = true
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *responseWriter) Write(b []byte) (int, error) {
+	if w.closed {
+		return len(b), nil
+	}
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	n, err := w.ResponseWriter.Write(b)
+	w.size += int64(n)
+	return n, err
+}
+
+func (w *responseWriter) Flush() {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (w *responseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func (w *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	conn, rw, err := hijack(w.ResponseWriter)
+	if err == nil {
+		w.hijacked = true
+		w.wroteHeader = true
+		if w.status == 0 {
+			w.status = http.StatusSwitchingProtocols
+		}
+	}
+	return conn, rw, err
+}
+
+// Indica si la conexión se tomó con Hijack
+func (w *responseWriter) Hijacked() bool {
+	return w.hijacked
+}
+
+// Status enviado al cliente (200 si el manejador no escribió nada)
+func (w *responseWriter) Status() int {
+	if w.status == 0 {
+		return http.StatusOK
+	}
+	return w.status
+}
+
+// Indica si las cabeceras ya se enviaron
+func (w *responseWriter) Written() bool {
+	return w.wroteHeader
+}
+
+// ResponseWriter que pasa la respuesta al cliente y guarda una copia
+// completa de status y cuerpo
+type recordWriter struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (w *recordWriter) WriteHeader(code int) {
+	// Igual que en responseWriter, un 1xx (Early Hints) no es la respuesta final
+	if w.status == 0 && (code >= 200 || code == http.StatusSwitchingProtocols) {
+		w.status = code
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *recordWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *recordWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (w *recordWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func (w *recordWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return hijack(w.ResponseWriter)
+}
+
+// ResponseWriter para HEAD: mantiene cabeceras y status pero descarta el cuerpo
+type headWriter struct {
+	http.ResponseWriter
+}
+
+func (w *headWriter) Write(b []byte) (int, error) {
+	return len(b), nil
+}
+
+func (w *headWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (w *headWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}